@@ -0,0 +1,68 @@
+// Package audit records authentication and account events in the append-only
+// audit log.
+package audit
+
+import (
+	"log"
+
+	"blog-api/config"
+	"blog-api/models"
+	"blog-api/textutil"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Event describes something that happened to an account
+type Event struct {
+	Action string
+	// ActorID is the user who did it, empty for anonymous requests
+	ActorID    string
+	TargetType string
+	TargetID   string
+	Outcome    string
+	// Reason explains a failure or denial, or adds detail to a success
+	Reason string
+}
+
+// Record writes an event for the request. The IP address and user agent come
+// from the request, and the actor defaults to the authenticated user. Failures
+// to write are logged rather than failing the request.
+func Record(c *gin.Context, event Event) {
+	if event.ActorID == "" {
+		if user, exists := c.Get("user"); exists {
+			event.ActorID = user.(models.User).ID
+		}
+	}
+
+	write(event, c.ClientIP(), c.Request.UserAgent())
+}
+
+// RecordSystem writes an event that did not come from a request, such as one
+// performed by a background worker
+func RecordSystem(event Event) {
+	write(event, "", "")
+}
+
+func write(event Event, ipAddress, userAgent string) {
+	entry := models.AuditLog{
+		ID:         uuid.New().String(),
+		Action:     event.Action,
+		TargetType: event.TargetType,
+		TargetID:   textutil.Truncate(event.TargetID, 255),
+		IPAddress:  textutil.Truncate(ipAddress, 45),
+		UserAgent:  textutil.Truncate(userAgent, 500),
+		Outcome:    event.Outcome,
+		Reason:     textutil.Truncate(event.Reason, 255),
+	}
+	if event.ActorID != "" {
+		entry.ActorID = &event.ActorID
+	}
+	if entry.Outcome == "" {
+		entry.Outcome = models.AuditOutcomeSuccess
+	}
+
+	if err := config.DB.Create(&entry).Error; err != nil {
+		log.Printf("Failed to write audit log entry %s: %v", event.Action, err)
+	}
+}
@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// clockSkew is the leeway allowed when checking exp, nbf, and iat
+const clockSkew = 30 * time.Second
+
+// SignAccessToken signs an access token carrying the given claims. The
+// standard iss, aud, iat, nbf, and exp claims are added here.
+func SignAccessToken(claims jwt.MapClaims, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims["iss"] = Keys.issuer
+	claims["aud"] = Keys.audience
+	claims["iat"] = now.Unix()
+	claims["nbf"] = now.Unix()
+	claims["exp"] = now.Add(ttl).Unix()
+
+	if Keys.algorithm == AlgorithmHS256 {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(Keys.secret)
+	}
+
+	key, err := Keys.current()
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.id
+	return token.SignedString(key.private)
+}
+
+// ParseAccessToken verifies an access token's signature and its iss, aud, exp,
+// nbf, and iat claims, and returns its claims
+func ParseAccessToken(tokenString string) (jwt.MapClaims, error) {
+	validMethods := []string{AlgorithmRS256, AlgorithmEdDSA}
+	if Keys.algorithm == AlgorithmHS256 {
+		validMethods = []string{AlgorithmHS256}
+	}
+
+	parser := jwt.NewParser(
+		jwt.WithValidMethods(validMethods),
+		jwt.WithIssuer(Keys.issuer),
+		jwt.WithAudience(Keys.audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(clockSkew),
+	)
+
+	token, err := parser.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if Keys.algorithm == AlgorithmHS256 {
+			return Keys.secret, nil
+		}
+
+		kid, _ := token.Header["kid"].(string)
+		key, err := Keys.lookup(kid)
+		if err != nil {
+			return nil, err
+		}
+		if key.method.Alg() != token.Method.Alg() {
+			return nil, jwt.ErrTokenSignatureInvalid
+		}
+		return key.public, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, jwt.ErrTokenInvalidClaims
+	}
+
+	// nbf is only checked by the parser when present, so require it here
+	if nbf, err := claims.GetNotBefore(); err != nil || nbf == nil {
+		return nil, errors.New("token is missing the nbf claim")
+	}
+
+	return claims, nil
+}
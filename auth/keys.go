@@ -0,0 +1,357 @@
+// Package auth signs and verifies access tokens. Asymmetric signing keys are
+// stored in the database, rotated on a schedule, and published as a JWKS so
+// that other services can verify tokens without sharing a secret.
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"blog-api/config"
+	"blog-api/models"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Supported signing algorithms
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+	AlgorithmHS256 = "HS256"
+)
+
+// reloadInterval limits how often an unknown kid triggers a reload from the database
+const reloadInterval = 10 * time.Second
+
+// Bounds for the time between checks for due rotations
+const (
+	minRotationCheckInterval = time.Minute
+	maxRotationCheckInterval = time.Hour
+)
+
+var errUnknownKey = errors.New("unknown signing key")
+
+// signingKey is a parsed SigningKey ready for use
+type signingKey struct {
+	id        string
+	method    jwt.SigningMethod
+	private   crypto.Signer
+	public    crypto.PublicKey
+	retiresAt time.Time
+	expiresAt time.Time
+}
+
+// KeyManager holds the signing keys shared by all API instances
+type KeyManager struct {
+	mu         sync.RWMutex
+	algorithm  string
+	secret     []byte
+	issuer     string
+	audience   string
+	rotation   time.Duration
+	grace      time.Duration
+	keys       []signingKey
+	lastReload time.Time
+}
+
+// Keys is the key manager used to sign and verify access tokens
+var Keys *KeyManager
+
+// Setup creates the key manager from the environment, makes sure a current
+// signing key exists, and starts the rotation loop
+func Setup() {
+	algorithm := config.JWTAlgorithm()
+	switch algorithm {
+	case AlgorithmRS256, AlgorithmEdDSA:
+	case AlgorithmHS256:
+		if config.JWTSecret() == "" {
+			log.Fatal("JWT_SECRET is required when JWT_ALGORITHM is HS256")
+		}
+	default:
+		log.Fatalf("Unsupported JWT_ALGORITHM %q, expected RS256, EdDSA, or HS256", algorithm)
+	}
+
+	// Retired keys must stay valid for at least as long as the tokens they signed
+	grace := config.JWTKeyGracePeriod()
+	if grace < config.AccessTokenTTL() {
+		grace = config.AccessTokenTTL()
+	}
+
+	Keys = &KeyManager{
+		algorithm: algorithm,
+		secret:    []byte(config.JWTSecret()),
+		issuer:    config.JWTIssuer(),
+		audience:  config.JWTAudience(),
+		rotation:  config.JWTKeyRotationInterval(),
+		grace:     grace,
+	}
+
+	if algorithm == AlgorithmHS256 {
+		return
+	}
+
+	if err := Keys.Rotate(); err != nil {
+		log.Fatal("Failed to prepare signing keys:", err)
+	}
+
+	// Check for due rotations well within the grace period, so the next key
+	// always exists before the current one retires
+	checkInterval := grace / 2
+	if checkInterval > maxRotationCheckInterval {
+		checkInterval = maxRotationCheckInterval
+	}
+	if checkInterval < minRotationCheckInterval {
+		checkInterval = minRotationCheckInterval
+	}
+
+	go func() {
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := Keys.Rotate(); err != nil {
+				log.Printf("Failed to rotate signing keys: %v", err)
+			}
+		}
+	}()
+}
+
+// Rotate removes expired keys and prepares the next signing key. The next key
+// is published one grace period before the current key retires, so verifiers
+// that cache the JWKS learn about it before any token is signed with it.
+// Running it concurrently on several instances at worst creates an extra key,
+// which is harmless because every unexpired key is published.
+func (m *KeyManager) Rotate() error {
+	now := time.Now()
+
+	if err := config.DB.Where("expires_at <= ?", now).Delete(&models.SigningKey{}).Error; err != nil {
+		return err
+	}
+
+	var latest models.SigningKey
+	err := config.DB.Where("algorithm = ?", m.algorithm).Order("retires_at DESC").First(&latest).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	if err != nil || latest.RetiresAt.Before(now.Add(m.grace)) {
+		// The new key takes over when the latest one retires
+		start := now
+		if err == nil && latest.RetiresAt.After(now) {
+			start = latest.RetiresAt
+		}
+
+		key, err := generateSigningKey(m.algorithm, start.Add(m.rotation), m.grace)
+		if err != nil {
+			return err
+		}
+		if err := config.DB.Create(key).Error; err != nil {
+			return err
+		}
+		log.Printf("Generated %s signing key %s", key.Algorithm, key.ID)
+	}
+
+	return m.reload()
+}
+
+// reload reads all unexpired keys from the database
+func (m *KeyManager) reload() error {
+	var records []models.SigningKey
+	if err := config.DB.Where("expires_at > ?", time.Now()).Order("created_at DESC").Find(&records).Error; err != nil {
+		return err
+	}
+
+	keys := make([]signingKey, 0, len(records))
+	for _, record := range records {
+		key, err := parseSigningKey(record)
+		if err != nil {
+			log.Printf("Skipping signing key %s: %v", record.ID, err)
+			continue
+		}
+		keys = append(keys, key)
+	}
+
+	// Sign with the key that retires first among those still active
+	sort.Slice(keys, func(i, j int) bool { return keys[i].retiresAt.Before(keys[j].retiresAt) })
+
+	m.mu.Lock()
+	m.keys = keys
+	m.lastReload = time.Now()
+	m.mu.Unlock()
+	return nil
+}
+
+// current returns the key new tokens are signed with. When no cached key is
+// usable, for example because a rotation check was missed, it rotates on demand.
+func (m *KeyManager) current() (signingKey, error) {
+	if key, ok := m.active(); ok {
+		return key, nil
+	}
+
+	if err := m.Rotate(); err != nil {
+		return signingKey{}, fmt.Errorf("no current signing key: %w", err)
+	}
+	if key, ok := m.active(); ok {
+		return key, nil
+	}
+	return signingKey{}, errors.New("no current signing key")
+}
+
+// active returns the cached key that signs new tokens right now
+func (m *KeyManager) active() (signingKey, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	for _, key := range m.keys {
+		if key.method.Alg() == m.algorithm && now.Before(key.retiresAt) {
+			return key, true
+		}
+	}
+	return signingKey{}, false
+}
+
+// lookup returns the unexpired key with the given kid, reloading from the
+// database once in a while in case another instance rotated keys
+func (m *KeyManager) lookup(kid string) (signingKey, error) {
+	if key, ok := m.find(kid); ok {
+		return key, nil
+	}
+
+	m.mu.RLock()
+	stale := time.Since(m.lastReload) > reloadInterval
+	m.mu.RUnlock()
+
+	if stale {
+		if err := m.reload(); err != nil {
+			return signingKey{}, err
+		}
+		if key, ok := m.find(kid); ok {
+			return key, nil
+		}
+	}
+
+	return signingKey{}, errUnknownKey
+}
+
+// find returns the cached unexpired key with the given kid
+func (m *KeyManager) find(kid string) (signingKey, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	for _, key := range m.keys {
+		if key.id == kid && now.Before(key.expiresAt) {
+			return key, true
+		}
+	}
+	return signingKey{}, false
+}
+
+// publicKeys returns every unexpired key
+func (m *KeyManager) publicKeys() []signingKey {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	keys := make([]signingKey, 0, len(m.keys))
+	for _, key := range m.keys {
+		if now.Before(key.expiresAt) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// generateSigningKey creates a new key pair for the algorithm
+func generateSigningKey(algorithm string, retiresAt time.Time, grace time.Duration) (*models.SigningKey, error) {
+	var private crypto.Signer
+	switch algorithm {
+	case AlgorithmRS256:
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, err
+		}
+		private = key
+	case AlgorithmEdDSA:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		private = key
+	default:
+		return nil, fmt.Errorf("cannot generate key for algorithm %q", algorithm)
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(private.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.SigningKey{
+		ID:            uuid.New().String(),
+		Algorithm:     algorithm,
+		PrivateKeyPEM: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER})),
+		PublicKeyPEM:  string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})),
+		RetiresAt:     retiresAt,
+		ExpiresAt:     retiresAt.Add(grace),
+	}, nil
+}
+
+// parseSigningKey decodes a stored key pair
+func parseSigningKey(record models.SigningKey) (signingKey, error) {
+	block, _ := pem.Decode([]byte(record.PrivateKeyPEM))
+	if block == nil {
+		return signingKey{}, errors.New("invalid private key PEM")
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return signingKey{}, err
+	}
+
+	private, ok := parsed.(crypto.Signer)
+	if !ok {
+		return signingKey{}, errors.New("private key cannot sign")
+	}
+
+	var method jwt.SigningMethod
+	switch record.Algorithm {
+	case AlgorithmRS256:
+		if _, ok := private.(*rsa.PrivateKey); !ok {
+			return signingKey{}, errors.New("RS256 key is not an RSA key")
+		}
+		method = jwt.SigningMethodRS256
+	case AlgorithmEdDSA:
+		if _, ok := private.(ed25519.PrivateKey); !ok {
+			return signingKey{}, errors.New("EdDSA key is not an Ed25519 key")
+		}
+		method = jwt.SigningMethodEdDSA
+	default:
+		return signingKey{}, fmt.Errorf("unsupported algorithm %q", record.Algorithm)
+	}
+
+	return signingKey{
+		id:        record.ID,
+		method:    method,
+		private:   private,
+		public:    private.Public(),
+		retiresAt: record.RetiresAt,
+		expiresAt: record.ExpiresAt,
+	}, nil
+}
@@ -0,0 +1,262 @@
+// Command mock-oidc is a minimal OpenID Connect provider for trying out and
+// testing social login locally. It approves every authorization request
+// without a login page and signs ID tokens with a key generated at startup.
+//
+// The signed-in user defaults to mock.user@example.com; pass login_hint (or
+// email) on the authorization URL to sign in as someone else.
+//
+//	go run ./cmd/mock-oidc -addr :9000 -client-id blog-api
+package main
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"log"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// grant is an issued authorization code or access token
+type grant struct {
+	email         string
+	nonce         string
+	redirectURI   string
+	codeChallenge string
+	expiresAt     time.Time
+}
+
+type provider struct {
+	issuer       string
+	clientID     string
+	clientSecret string
+	key          *rsa.PrivateKey
+
+	mu           sync.Mutex
+	codes        map[string]grant
+	accessTokens map[string]grant
+}
+
+func main() {
+	addr := flag.String("addr", ":9000", "listen address")
+	issuer := flag.String("issuer", "http://localhost:9000", "issuer URL, must match how clients reach this server")
+	clientID := flag.String("client-id", "blog-api", "accepted client ID")
+	clientSecret := flag.String("client-secret", "", "required client secret, empty for public clients")
+	flag.Parse()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		log.Fatal("Failed to generate signing key:", err)
+	}
+
+	p := &provider{
+		issuer:       strings.TrimRight(*issuer, "/"),
+		clientID:     *clientID,
+		clientSecret: *clientSecret,
+		key:          key,
+		codes:        map[string]grant{},
+		accessTokens: map[string]grant{},
+	}
+
+	http.HandleFunc("/.well-known/openid-configuration", p.discovery)
+	http.HandleFunc("/authorize", p.authorize)
+	http.HandleFunc("/token", p.token)
+	http.HandleFunc("/userinfo", p.userinfo)
+	http.HandleFunc("/jwks", p.jwks)
+
+	log.Printf("Mock OIDC provider %s listening on %s", p.issuer, *addr)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
+
+func (p *provider) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                p.issuer,
+		"authorization_endpoint":                p.issuer + "/authorize",
+		"token_endpoint":                        p.issuer + "/token",
+		"userinfo_endpoint":                     p.issuer + "/userinfo",
+		"jwks_uri":                              p.issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+// authorize approves the request and redirects back with a code
+func (p *provider) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("client_id") != p.clientID || query.Get("response_type") != "code" {
+		http.Error(w, "invalid client_id or response_type", http.StatusBadRequest)
+		return
+	}
+	if query.Get("code_challenge") == "" || query.Get("code_challenge_method") != "S256" {
+		http.Error(w, "PKCE with S256 is required", http.StatusBadRequest)
+		return
+	}
+
+	redirectURI, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || redirectURI.Scheme == "" {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	email := query.Get("login_hint")
+	if email == "" {
+		email = query.Get("email")
+	}
+	if email == "" {
+		email = "mock.user@example.com"
+	}
+
+	code := randomString()
+	p.mu.Lock()
+	p.codes[code] = grant{
+		email:         email,
+		nonce:         query.Get("nonce"),
+		redirectURI:   redirectURI.String(),
+		codeChallenge: query.Get("code_challenge"),
+		expiresAt:     time.Now().Add(time.Minute),
+	}
+	p.mu.Unlock()
+
+	callback := redirectURI.Query()
+	callback.Set("code", code)
+	callback.Set("state", query.Get("state"))
+	redirectURI.RawQuery = callback.Encode()
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+// token redeems a code after checking the client, redirect URI, and PKCE verifier
+func (p *provider) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		tokenError(w, "invalid_request")
+		return
+	}
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID != p.clientID || (p.clientSecret != "" && clientSecret != p.clientSecret) {
+		tokenError(w, "invalid_client")
+		return
+	}
+
+	code := r.PostForm.Get("code")
+	p.mu.Lock()
+	g, found := p.codes[code]
+	delete(p.codes, code)
+	p.mu.Unlock()
+
+	if r.PostForm.Get("grant_type") != "authorization_code" || !found || time.Now().After(g.expiresAt) ||
+		r.PostForm.Get("redirect_uri") != g.redirectURI {
+		tokenError(w, "invalid_grant")
+		return
+	}
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != g.codeChallenge {
+		tokenError(w, "invalid_grant")
+		return
+	}
+
+	now := time.Now()
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":                p.issuer,
+		"aud":                p.clientID,
+		"sub":                subject(g.email),
+		"email":              g.email,
+		"email_verified":     true,
+		"name":               strings.SplitN(g.email, "@", 2)[0],
+		"preferred_username": strings.SplitN(g.email, "@", 2)[0],
+		"nonce":              g.nonce,
+		"iat":                now.Unix(),
+		"exp":                now.Add(5 * time.Minute).Unix(),
+	})
+	idToken.Header["kid"] = "mock"
+	signed, err := idToken.SignedString(p.key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	accessToken := randomString()
+	g.expiresAt = now.Add(time.Hour)
+	p.mu.Lock()
+	p.accessTokens[accessToken] = g
+	p.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     signed,
+	})
+}
+
+func (p *provider) userinfo(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	g, found := p.accessTokens[strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")]
+	p.mu.Unlock()
+
+	if !found || time.Now().After(g.expiresAt) {
+		http.Error(w, "invalid access token", http.StatusUnauthorized)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"sub":            subject(g.email),
+		"email":          g.email,
+		"email_verified": true,
+	})
+}
+
+func (p *provider) jwks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "mock",
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(p.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(p.key.E)).Bytes()),
+		}},
+	})
+}
+
+// subject derives a stable subject from the email so repeated sign-ins match
+func subject(email string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(email)))
+	return base64.RawURLEncoding.EncodeToString(sum[:12])
+}
+
+func randomString() string {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+func tokenError(w http.ResponseWriter, code string) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{"error": code})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
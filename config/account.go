@@ -0,0 +1,32 @@
+package config
+
+import (
+	"log"
+	"time"
+)
+
+// Account deletion policies
+const (
+	// DeletionPolicyAnonymize keeps posts and comments but strips the account of personal data
+	DeletionPolicyAnonymize = "anonymize"
+	// DeletionPolicyDelete removes the account together with its content
+	DeletionPolicyDelete = "delete"
+)
+
+// AccountDeletionGracePeriod returns how long a deletion request can be
+// cancelled before the account is deleted
+func AccountDeletionGracePeriod() time.Duration {
+	return GetEnvDuration("ACCOUNT_DELETION_GRACE_PERIOD", 30*24*time.Hour)
+}
+
+// AccountDeletionPolicy returns what happens to an account's content once its
+// deletion grace period is over
+func AccountDeletionPolicy() string {
+	switch policy := GetEnv("ACCOUNT_DELETION_POLICY", DeletionPolicyAnonymize); policy {
+	case DeletionPolicyAnonymize, DeletionPolicyDelete:
+		return policy
+	default:
+		log.Printf("Unknown ACCOUNT_DELETION_POLICY %q, falling back to %s", policy, DeletionPolicyAnonymize)
+		return DeletionPolicyAnonymize
+	}
+}
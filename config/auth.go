@@ -0,0 +1,179 @@
+package config
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"blog-api/models"
+)
+
+// AccessTokenTTL returns how long an issued access token stays valid
+func AccessTokenTTL() time.Duration {
+	return GetEnvDuration("ACCESS_TOKEN_TTL", 15*time.Minute)
+}
+
+// RefreshTokenTTL returns how long an issued refresh token stays valid
+func RefreshTokenTTL() time.Duration {
+	return GetEnvDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour)
+}
+
+// JWTAlgorithm returns the algorithm used to sign access tokens: RS256, EdDSA, or HS256
+func JWTAlgorithm() string {
+	return GetEnv("JWT_ALGORITHM", "RS256")
+}
+
+// JWTSecret returns the shared secret used when JWT_ALGORITHM is HS256
+func JWTSecret() string {
+	return GetEnv("JWT_SECRET", "")
+}
+
+// JWTIssuer returns the "iss" claim of issued access tokens
+func JWTIssuer() string {
+	return GetEnv("JWT_ISSUER", AppURL())
+}
+
+// JWTAudience returns the "aud" claim of issued access tokens
+func JWTAudience() string {
+	return GetEnv("JWT_AUDIENCE", "blog-api")
+}
+
+// JWTKeyRotationInterval returns how long a signing key is used before a new one replaces it
+func JWTKeyRotationInterval() time.Duration {
+	return GetEnvDuration("JWT_KEY_ROTATION_INTERVAL", 30*24*time.Hour)
+}
+
+// JWTKeyGracePeriod returns how long a retired signing key is still accepted
+// and published, so that tokens it signed stay valid until they expire
+func JWTKeyGracePeriod() time.Duration {
+	return GetEnvDuration("JWT_KEY_GRACE_PERIOD", 24*time.Hour)
+}
+
+// PasswordResetTokenTTL returns how long a password reset link stays valid
+func PasswordResetTokenTTL() time.Duration {
+	return GetEnvDuration("PASSWORD_RESET_TOKEN_TTL", time.Hour)
+}
+
+// EmailVerificationTokenTTL returns how long an email verification link stays valid
+func EmailVerificationTokenTTL() time.Duration {
+	return GetEnvDuration("EMAIL_VERIFICATION_TOKEN_TTL", 48*time.Hour)
+}
+
+// RequireEmailVerification reports whether users must verify their email
+// address before they can create posts or comments
+func RequireEmailVerification() bool {
+	return GetEnvBool("REQUIRE_EMAIL_VERIFICATION", false)
+}
+
+// MagicLinkTTL returns how long an emailed sign-in link stays valid
+func MagicLinkTTL() time.Duration {
+	return GetEnvDuration("MAGIC_LINK_TTL", 15*time.Minute)
+}
+
+// MagicLinkMaxPerHour returns how many sign-in links can be requested per email address each hour
+func MagicLinkMaxPerHour() int {
+	return GetEnvInt("MAGIC_LINK_MAX_PER_HOUR", 3)
+}
+
+// TwoFactorChallengeTTL returns how long a user has to enter their TOTP code after the password step
+func TwoFactorChallengeTTL() time.Duration {
+	return GetEnvDuration("TWO_FACTOR_CHALLENGE_TTL", 5*time.Minute)
+}
+
+// TOTPIssuer returns the issuer name shown in authenticator apps
+func TOTPIssuer() string {
+	return GetEnv("TOTP_ISSUER", "Blog API")
+}
+
+// LoginMaxAccountFailures returns how many consecutive failed logins lock an account
+func LoginMaxAccountFailures() int {
+	return GetEnvInt("LOGIN_MAX_ACCOUNT_FAILURES", 5)
+}
+
+// LoginMaxIPFailures returns how many consecutive failed logins lock a client IP
+func LoginMaxIPFailures() int {
+	return GetEnvInt("LOGIN_MAX_IP_FAILURES", 20)
+}
+
+// LoginLockoutDuration returns how long a lockout lasts. Failures older than
+// this are also forgotten.
+func LoginLockoutDuration() time.Duration {
+	return GetEnvDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute)
+}
+
+// LoginBackoffBase returns the delay required after the first failed login,
+// which doubles with each further failure
+func LoginBackoffBase() time.Duration {
+	return GetEnvDuration("LOGIN_BACKOFF_BASE", time.Second)
+}
+
+// DefaultUserRole returns the role assigned to newly registered users
+func DefaultUserRole() string {
+	role := GetEnv("DEFAULT_USER_ROLE", models.RoleAuthor)
+	if !models.IsValidRole(role) {
+		return models.RoleAuthor
+	}
+	return role
+}
+
+// OAuthStateTTL returns how long a user has to finish signing in at an OAuth provider
+func OAuthStateTTL() time.Duration {
+	return GetEnvDuration("OAUTH_STATE_TTL", 10*time.Minute)
+}
+
+// OAuthRedirectURL returns the callback URL registered with the provider. An
+// OAUTH_REDIRECT_URL such as https://app.example.com/oauth/{provider} lets a
+// frontend receive the code and post it to the callback endpoint instead.
+func OAuthRedirectURL(provider string) string {
+	template := GetEnv("OAUTH_REDIRECT_URL", AppURL()+"/api/v1/auth/oauth/{provider}/callback")
+	return strings.ReplaceAll(template, "{provider}", provider)
+}
+
+// WebAuthnChallengeTTL returns how long a passkey registration or sign-in may take
+func WebAuthnChallengeTTL() time.Duration {
+	return GetEnvDuration("WEBAUTHN_CHALLENGE_TTL", 5*time.Minute)
+}
+
+// WebAuthnRPID returns the domain passkeys are registered for, by default the host of APP_URL
+func WebAuthnRPID() string {
+	if rpID := GetEnv("WEBAUTHN_RP_ID", ""); rpID != "" {
+		return rpID
+	}
+	if appURL, err := url.Parse(AppURL()); err == nil && appURL.Hostname() != "" {
+		return appURL.Hostname()
+	}
+	return "localhost"
+}
+
+// WebAuthnRPName returns the name authenticators show when creating a passkey
+func WebAuthnRPName() string {
+	return GetEnv("WEBAUTHN_RP_NAME", TOTPIssuer())
+}
+
+// WebAuthnOrigins returns the origins of the pages allowed to use passkeys,
+// by default the origin of APP_URL
+func WebAuthnOrigins() []string {
+	var origins []string
+	for _, origin := range strings.Split(GetEnv("WEBAUTHN_ORIGINS", ""), ",") {
+		if origin = strings.TrimRight(strings.TrimSpace(origin), "/"); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	if len(origins) == 0 {
+		if appURL, err := url.Parse(AppURL()); err == nil {
+			origins = append(origins, appURL.Scheme+"://"+appURL.Host)
+		}
+	}
+	return origins
+}
+
+// WebAuthnRequireUserVerification returns whether passkeys must verify the
+// user with a PIN or biometric, rather than only a touch
+func WebAuthnRequireUserVerification() bool {
+	return GetEnvBool("WEBAUTHN_REQUIRE_USER_VERIFICATION", false)
+}
+
+// AppURL returns the public base URL used to build links sent to users
+func AppURL() string {
+	return GetEnv("APP_URL", "http://localhost:8080")
+}
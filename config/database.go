@@ -3,7 +3,6 @@ package config
 import (
 	"fmt"
 	"log"
-	"os"
 
 	"blog-api/models"
 
@@ -18,11 +17,11 @@ func ConnectDatabase() {
 	var err error
 	
 	// Get database configuration from environment variables
-	host := getEnv("DB_HOST", "localhost")
-	port := getEnv("DB_PORT", "3306")
-	user := getEnv("DB_USER", "root")
-	password := getEnv("DB_PASSWORD", "")
-	dbname := getEnv("DB_NAME", "blog_api")
+	host := GetEnv("DB_HOST", "localhost")
+	port := GetEnv("DB_PORT", "3306")
+	user := GetEnv("DB_USER", "root")
+	password := GetEnv("DB_PASSWORD", "")
+	dbname := GetEnv("DB_NAME", "blog_api")
 
 	// Create DSN (Data Source Name)
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
@@ -31,6 +30,8 @@ func ConnectDatabase() {
 	// Connect to database
 	DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Info),
+		// Report unique index violations as gorm.ErrDuplicatedKey
+		TranslateError: true,
 	})
 
 	if err != nil {
@@ -43,18 +44,37 @@ func ConnectDatabase() {
 		&models.Post{},
 		&models.Comment{},
 		&models.Like{},
+		&models.RefreshToken{},
+		&models.Session{},
+		&models.UserToken{},
+		&models.RecoveryCode{},
+		&models.APIKey{},
+		&models.LoginThrottle{},
+		&models.SigningKey{},
+		&models.OAuthIdentity{},
+		&models.OAuthState{},
+		&models.Invite{},
+		&models.PasswordHistory{},
+		&models.AuditLog{},
+		&models.WebAuthnCredential{},
+		&models.WebAuthnChallenge{},
+		&models.Follow{},
+		&models.Block{},
+		&models.Mute{},
+		&models.PostSlug{},
+		&models.PostRevision{},
 	)
 
 	if err != nil {
 		log.Fatal("Failed to migrate database:", err)
 	}
 
-	fmt.Println("Database connected successfully!")
-}
-
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+	// Posts from before the publishing workflow count as published when they were created
+	if err := DB.Model(&models.Post{}).
+		Where("status = ? AND published_at IS NULL", models.PostStatusPublished).
+		Update("published_at", gorm.Expr("created_at")).Error; err != nil {
+		log.Fatal("Failed to backfill post publish dates:", err)
 	}
-	return defaultValue
+
+	fmt.Println("Database connected successfully!")
 }
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// GetEnv returns the value of an environment variable or a default value
+func GetEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// GetEnvDuration parses an environment variable as a duration (e.g. "15m", "720h")
+func GetEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return defaultValue
+	}
+	return duration
+}
+
+// GetEnvInt parses an environment variable as an integer
+func GetEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return defaultValue
+	}
+	return number
+}
+
+// GetEnvBool parses an environment variable as a boolean
+func GetEnvBool(key string, defaultValue bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	flag, err := strconv.ParseBool(value)
+	if err != nil {
+		return defaultValue
+	}
+	return flag
+}
@@ -0,0 +1,15 @@
+package config
+
+import (
+	"time"
+)
+
+// PostSchedulerInterval returns how often scheduled posts are checked and
+// published. Values that are zero or negative fall back to the default.
+func PostSchedulerInterval() time.Duration {
+	const defaultInterval = time.Minute
+	if interval := GetEnvDuration("POST_SCHEDULER_INTERVAL", defaultInterval); interval > 0 {
+		return interval
+	}
+	return defaultInterval
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"log"
+	"strings"
+)
+
+// Registration modes
+const (
+	// RegistrationOpen lets anyone register
+	RegistrationOpen = "open"
+	// RegistrationClosed turns registration off
+	RegistrationClosed = "closed"
+	// RegistrationInviteOnly requires an admin-issued invite code
+	RegistrationInviteOnly = "invite_only"
+	// RegistrationDomain only accepts email addresses at the allowed domains
+	RegistrationDomain = "domain"
+)
+
+// RegistrationMode returns who may create an account
+func RegistrationMode() string {
+	switch mode := GetEnv("REGISTRATION_MODE", RegistrationOpen); mode {
+	case RegistrationOpen, RegistrationClosed, RegistrationInviteOnly, RegistrationDomain:
+		return mode
+	default:
+		// Fail closed rather than opening registration by accident
+		log.Printf("Unknown REGISTRATION_MODE %q, treating registration as closed", mode)
+		return RegistrationClosed
+	}
+}
+
+// RegistrationAllowedDomains returns the email domains accepted in domain mode
+func RegistrationAllowedDomains() []string {
+	var domains []string
+	for _, domain := range strings.Split(GetEnv("REGISTRATION_ALLOWED_DOMAINS", ""), ",") {
+		domain = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(domain), "@"))
+		if domain != "" {
+			domains = append(domains, domain)
+		}
+	}
+	return domains
+}
@@ -0,0 +1,144 @@
+// Package diff computes line-level differences between two texts using the
+// Myers O(ND) algorithm, the same approach taken by diff(1) and git.
+package diff
+
+import (
+	"errors"
+	"strings"
+)
+
+// MaxEditDistance is the largest number of inserted and deleted lines a diff
+// may have. The search keeps O(D²) state for an edit distance D, so texts that
+// differ more than this are rejected instead of compared.
+const MaxEditDistance = 1000
+
+// ErrTooManyChanges is returned when two texts differ by more than MaxEditDistance lines
+var ErrTooManyChanges = errors.New("diff: texts differ by too many lines")
+
+// Line operations
+const (
+	OpEqual  = "equal"
+	OpInsert = "insert"
+	OpDelete = "delete"
+)
+
+// Line is one line of a diff. OldLine and NewLine are 1-based line numbers in
+// the old and new text, or 0 when the line does not appear there.
+type Line struct {
+	Op      string `json:"op"`
+	Text    string `json:"text"`
+	OldLine int    `json:"old_line,omitempty"`
+	NewLine int    `json:"new_line,omitempty"`
+}
+
+// Lines returns the shortest edit script turning a into b, line by line, or
+// ErrTooManyChanges if it would be longer than MaxEditDistance
+func Lines(a, b string) ([]Line, error) {
+	return diff(splitLines(a), splitLines(b), MaxEditDistance)
+}
+
+// Stats counts the inserted and deleted lines of a diff
+func Stats(lines []Line) (inserted, deleted int) {
+	for _, line := range lines {
+		switch line.Op {
+		case OpInsert:
+			inserted++
+		case OpDelete:
+			deleted++
+		}
+	}
+	return inserted, deleted
+}
+
+// splitLines splits text into lines, treating \r\n like \n
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diff runs the forward Myers search for at most maxEdits steps
+func diff(a, b []string, maxEdits int) ([]Line, error) {
+	n, m := len(a), len(b)
+	maxD := n + m
+	if maxD > maxEdits {
+		maxD = maxEdits
+	}
+	offset := maxD + 1
+
+	// v[k+offset] is the furthest x reached on diagonal k. trace[d] keeps
+	// v[-d..d] after step d, indexed by k+d, so the path can be walked back
+	// without copying all of v on every step.
+	v := make([]int, 2*maxD+3)
+	var trace [][]int
+
+	for d := 0; d <= maxD; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[k-1+offset] < v[k+1+offset]) {
+				x = v[k+1+offset] // move down: insert from b
+			} else {
+				x = v[k-1+offset] + 1 // move right: delete from a
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[k+offset] = x
+			if x >= n && y >= m {
+				return backtrack(a, b, trace, d), nil
+			}
+		}
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+	}
+	return nil, ErrTooManyChanges
+}
+
+// backtrack walks the trace from the end of both texts to the start and
+// returns the edit script in order. d is the edit distance that was reached.
+func backtrack(a, b []string, trace [][]int, d int) []Line {
+	x, y := len(a), len(b)
+	var reversed []Line
+
+	for ; d > 0; d-- {
+		k := x - y
+		prev := trace[d-1]
+		prevOffset := d - 1
+
+		var prevK int
+		if k == -d || (k != d && prev[k-1+prevOffset] < prev[k+1+prevOffset]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := prev[prevK+prevOffset]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			reversed = append(reversed, Line{Op: OpEqual, Text: a[x-1], OldLine: x, NewLine: y})
+			x--
+			y--
+		}
+		if x == prevX {
+			reversed = append(reversed, Line{Op: OpInsert, Text: b[y-1], NewLine: y})
+		} else {
+			reversed = append(reversed, Line{Op: OpDelete, Text: a[x-1], OldLine: x})
+		}
+		x, y = prevX, prevY
+	}
+
+	for x > 0 && y > 0 {
+		reversed = append(reversed, Line{Op: OpEqual, Text: a[x-1], OldLine: x, NewLine: y})
+		x--
+		y--
+	}
+
+	lines := make([]Line, len(reversed))
+	for i, line := range reversed {
+		lines[len(reversed)-1-i] = line
+	}
+	return lines
+}
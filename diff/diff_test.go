@@ -0,0 +1,139 @@
+package diff
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// apply rebuilds both texts from a diff and checks the line numbers on the way
+func apply(t *testing.T, lines []Line) (oldText, newText []string) {
+	t.Helper()
+	for _, line := range lines {
+		switch line.Op {
+		case OpEqual:
+			oldText = append(oldText, line.Text)
+			newText = append(newText, line.Text)
+		case OpDelete:
+			oldText = append(oldText, line.Text)
+		case OpInsert:
+			newText = append(newText, line.Text)
+		default:
+			t.Fatalf("unknown op %q", line.Op)
+		}
+		if line.Op != OpInsert && line.OldLine != len(oldText) {
+			t.Fatalf("old line number = %d, want %d", line.OldLine, len(oldText))
+		}
+		if line.Op != OpDelete && line.NewLine != len(newText) {
+			t.Fatalf("new line number = %d, want %d", line.NewLine, len(newText))
+		}
+	}
+	return oldText, newText
+}
+
+func checkRoundTrip(t *testing.T, a, b string) []Line {
+	t.Helper()
+	lines, err := Lines(a, b)
+	if err != nil {
+		t.Fatalf("Lines() error = %v", err)
+	}
+	oldText, newText := apply(t, lines)
+	if got, want := strings.Join(oldText, "\n"), strings.Join(splitLines(a), "\n"); got != want {
+		t.Fatalf("old text not reproduced:\ngot  %q\nwant %q", got, want)
+	}
+	if got, want := strings.Join(newText, "\n"), strings.Join(splitLines(b), "\n"); got != want {
+		t.Fatalf("new text not reproduced:\ngot  %q\nwant %q", got, want)
+	}
+	return lines
+}
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name              string
+		a, b              string
+		inserted, deleted int
+	}{
+		{"both empty", "", "", 0, 0},
+		{"from empty", "", "one\ntwo\n", 2, 0},
+		{"to empty", "one\ntwo\n", "", 0, 2},
+		{"equal", "one\ntwo\n", "one\ntwo\n", 0, 0},
+		{"crlf", "one\r\ntwo\r\n", "one\ntwo\n", 0, 0},
+		{"insert middle", "a\nc", "a\nb\nc", 1, 0},
+		{"delete middle", "a\nb\nc", "a\nc", 0, 1},
+		{"replace", "a\nb\nc", "a\nx\nc", 1, 1},
+		{"classic", "a\nb\nc\na\nb\nb\na", "c\nb\na\nb\na\nc", 2, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := checkRoundTrip(t, tt.a, tt.b)
+			inserted, deleted := Stats(lines)
+			if inserted != tt.inserted || deleted != tt.deleted {
+				t.Errorf("Stats() = +%d -%d, want +%d -%d", inserted, deleted, tt.inserted, tt.deleted)
+			}
+		})
+	}
+}
+
+func TestLinesRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	words := []string{"a", "b", "c", "d"}
+	randomText := func() string {
+		lines := make([]string, rng.Intn(30))
+		for i := range lines {
+			lines[i] = words[rng.Intn(len(words))]
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	for i := 0; i < 500; i++ {
+		checkRoundTrip(t, randomText(), randomText())
+	}
+}
+
+func TestLinesLarge(t *testing.T) {
+	numbered := func(n int, prefix string) []string {
+		lines := make([]string, n)
+		for i := range lines {
+			lines[i] = fmt.Sprintf("%s line %d", prefix, i)
+		}
+		return lines
+	}
+
+	// A long post with scattered edits stays well within the limit
+	oldLines := numbered(20000, "same")
+	newLines := append([]string(nil), oldLines...)
+	for i := 0; i < len(newLines); i += 100 {
+		newLines[i] = fmt.Sprintf("edited line %d", i)
+	}
+	lines := checkRoundTrip(t, strings.Join(oldLines, "\n"), strings.Join(newLines, "\n"))
+	if inserted, deleted := Stats(lines); inserted != 200 || deleted != 200 {
+		t.Errorf("Stats() = +%d -%d, want +200 -200", inserted, deleted)
+	}
+
+	tests := []struct {
+		name    string
+		a, b    []string
+		wantErr error
+	}{
+		{"at the limit", numbered(MaxEditDistance/2, "old"), numbered(MaxEditDistance/2, "new"), nil},
+		{"over the limit", numbered(MaxEditDistance/2+1, "old"), numbered(MaxEditDistance/2, "new"), ErrTooManyChanges},
+		{"completely different", numbered(20000, "old"), numbered(20000, "new"), ErrTooManyChanges},
+		{"all inserted", nil, numbered(MaxEditDistance+1, "new"), ErrTooManyChanges},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := strings.Join(tt.a, "\n"), strings.Join(tt.b, "\n")
+			if tt.wantErr == nil {
+				checkRoundTrip(t, a, b)
+				return
+			}
+			if _, err := Lines(a, b); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Lines() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"blog-api/audit"
+	"blog-api/config"
+	"blog-api/mail"
+	"blog-api/models"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// accountDeletionBatchSize limits how many accounts one purge run deletes
+const accountDeletionBatchSize = 100
+
+// DeleteAccount handles a user asking for their account to be deleted. The
+// account is deleted once the grace period is over; until then signing in
+// still works and the request can be cancelled.
+func DeleteAccount(c *gin.Context) {
+	// Get user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	userModel := user.(models.User)
+
+	// The body is optional for accounts without a password
+	var req models.AccountDeletionRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if userModel.DeletionScheduledAt != nil {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":                 "Account deletion is already scheduled",
+			"deletion_scheduled_at": userModel.DeletionScheduledAt,
+		})
+		return
+	}
+
+	if userModel.PasswordHash != "" {
+		if err := bcrypt.CompareHashAndPassword([]byte(userModel.PasswordHash), []byte(req.Password)); err != nil {
+			recordAccountFailure(c, models.AuditActionDeletionRequest, userModel.ID, "Password is incorrect")
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Password is incorrect"})
+			return
+		}
+	}
+
+	deletionScheduledAt := time.Now().Add(config.AccountDeletionGracePeriod())
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&userModel).Update("deletion_scheduled_at", deletionScheduledAt).Error; err != nil {
+			return err
+		}
+
+		// Keep only the session used to make the request, so the user can still cancel
+		if err := revokeUserSessions(tx, userModel.ID, c.GetString("session_id")); err != nil {
+			return err
+		}
+
+		return tx.Model(&models.APIKey{}).
+			Where("user_id = ? AND revoked_at IS NULL", userModel.ID).
+			Update("revoked_at", time.Now()).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to schedule account deletion"})
+		return
+	}
+
+	recordAccountEvent(c, models.AuditActionDeletionRequest, userModel.ID)
+
+	if err := sendAccountDeletionEmail(userModel, deletionScheduledAt); err != nil {
+		log.Printf("Failed to send account deletion email to user %s: %v", userModel.ID, err)
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":               "Account deletion scheduled. Sign in and cancel before the deletion date to keep your account",
+		"deletion_scheduled_at": deletionScheduledAt,
+		"policy":                config.AccountDeletionPolicy(),
+	})
+}
+
+// CancelAccountDeletion handles keeping an account that is scheduled for deletion
+func CancelAccountDeletion(c *gin.Context) {
+	// Get user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	userModel := user.(models.User)
+
+	if userModel.DeletionScheduledAt == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Account deletion is not scheduled"})
+		return
+	}
+
+	if err := config.DB.Model(&userModel).Update("deletion_scheduled_at", nil).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel account deletion"})
+		return
+	}
+
+	recordAccountEvent(c, models.AuditActionDeletionCancel, userModel.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Account deletion cancelled",
+	})
+}
+
+// StartAccountDeletionWorker periodically deletes accounts whose deletion
+// grace period is over
+func StartAccountDeletionWorker() {
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for {
+			if err := purgeScheduledAccounts(); err != nil {
+				log.Printf("Failed to purge deleted accounts: %v", err)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// purgeScheduledAccounts deletes or anonymizes the accounts that are due,
+// according to the configured policy. Several instances may run it at once:
+// each account is claimed with a conditional update so only one processes it.
+func purgeScheduledAccounts() error {
+	var userIDs []string
+	if err := config.DB.Model(&models.User{}).
+		Where("deletion_scheduled_at <= ?", time.Now()).
+		Limit(accountDeletionBatchSize).
+		Pluck("id", &userIDs).Error; err != nil {
+		return err
+	}
+
+	policy := config.AccountDeletionPolicy()
+	for _, userID := range userIDs {
+		err := config.DB.Transaction(func(tx *gorm.DB) error {
+			result := tx.Model(&models.User{}).
+				Where("id = ? AND deletion_scheduled_at <= ?", userID, time.Now()).
+				Update("deletion_scheduled_at", nil)
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+
+			if policy == config.DeletionPolicyDelete {
+				return hardDeleteUser(tx, userID)
+			}
+			return anonymizeUser(tx, userID)
+		})
+		if err != nil {
+			log.Printf("Failed to delete account %s: %v", userID, err)
+			continue
+		}
+		log.Printf("Deleted account %s (%s)", userID, policy)
+		audit.RecordSystem(audit.Event{
+			Action:     models.AuditActionDeletionComplete,
+			TargetType: models.AuditTargetUser,
+			TargetID:   userID,
+			Reason:     policy,
+		})
+	}
+
+	return nil
+}
+
+// anonymizeUser strips an account of personal data, follows, blocks, and credentials while
+// keeping its posts, comments, and likes under a placeholder name
+func anonymizeUser(tx *gorm.DB, userID string) error {
+	var user models.User
+	if err := tx.First(&user, "id = ?", userID).Error; err != nil {
+		return err
+	}
+
+	if err := deleteUserFollows(tx.Unscoped(), userID); err != nil {
+		return err
+	}
+	if err := deleteUserBlocks(tx.Unscoped(), userID); err != nil {
+		return err
+	}
+	if err := deleteUserCredentials(tx.Unscoped(), userID); err != nil {
+		return err
+	}
+	if err := tx.Where("id = ?", accountThrottleKey(user.Email)).Delete(&models.LoginThrottle{}).Error; err != nil {
+		return err
+	}
+
+	placeholder := "deleted-" + strings.ReplaceAll(userID, "-", "")[:12]
+	return tx.Model(&user).Updates(map[string]interface{}{
+		"username":                placeholder,
+		"email":                   placeholder + "@deleted.invalid",
+		"password_hash":           "",
+		"email_verified_at":       nil,
+		"pending_email":           nil,
+		"role":                    models.RoleUser,
+		"totp_secret":             "",
+		"totp_enabled_at":         nil,
+		"totp_last_used_step":     0,
+		"display_name":            "Deleted user",
+		"bio":                     "",
+		"website":                 "",
+		"avatar_url":              "",
+		"suspended_at":            nil,
+		"suspended_until":         nil,
+		"suspension_reason":       "",
+		"password_reset_required": false,
+		"anonymized_at":           time.Now(),
+	}).Error
+}
+
+// sendAccountDeletionEmail confirms a deletion request and explains how to cancel it
+func sendAccountDeletionEmail(user models.User, deletionScheduledAt time.Time) error {
+	return mail.Send(mail.Message{
+		To:      user.Email,
+		Subject: "Your account is scheduled for deletion",
+		Body: fmt.Sprintf("Hi %s,\n\nWe received a request to delete your account. It will be deleted on %s.\n\nTo keep your account, sign in at %s and cancel the deletion before then.\n",
+			user.Username, deletionScheduledAt.UTC().Format(time.RFC1123), config.AppURL()),
+	})
+}
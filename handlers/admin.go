@@ -0,0 +1,438 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"blog-api/config"
+	"blog-api/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ListUsers handles listing users with pagination and filters
+func ListUsers(c *gin.Context) {
+	// Get pagination parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	query := config.DB.Model(&models.User{})
+
+	// Filter by search term
+	if search := c.Query("search"); search != "" {
+		like := "%" + search + "%"
+		query = query.Where("username LIKE ? OR email LIKE ?", like, like)
+	}
+
+	// Filter by status
+	now := time.Now()
+	switch c.Query("status") {
+	case "":
+	case models.UserStatusSuspended:
+		query = query.Where("suspended_at IS NOT NULL AND (suspended_until IS NULL OR suspended_until > ?)", now)
+	case models.UserStatusActive:
+		query = query.Where("suspended_at IS NULL OR suspended_until <= ?", now)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status, expected active or suspended"})
+		return
+	}
+
+	// Filter by role
+	if role := c.Query("role"); role != "" {
+		query = query.Where("role = ?", role)
+	}
+
+	// Filter by creation date
+	if createdAfter := c.Query("created_after"); createdAfter != "" {
+		t, err := time.Parse(time.RFC3339, createdAfter)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid created_after, expected RFC 3339 timestamp"})
+			return
+		}
+		query = query.Where("created_at >= ?", t)
+	}
+	if createdBefore := c.Query("created_before"); createdBefore != "" {
+		t, err := time.Parse(time.RFC3339, createdBefore)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid created_before, expected RFC 3339 timestamp"})
+			return
+		}
+		query = query.Where("created_at < ?", t)
+	}
+
+	// Count matching users
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+		return
+	}
+
+	var users []models.User
+	if err := query.Offset(offset).Limit(limit).Order("created_at DESC").Find(&users).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+		return
+	}
+
+	// Convert to response format
+	usersResponse := make([]models.AdminUserResponse, 0, len(users))
+	for _, user := range users {
+		usersResponse = append(usersResponse, convertUserToAdminResponse(user))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"users": usersResponse,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+// GetUser handles getting a single user by ID
+func GetUser(c *gin.Context) {
+	var user models.User
+	if err := config.DB.First(&user, "id = ?", c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user": convertUserToAdminResponse(user),
+	})
+}
+
+// UpdateUserRole handles changing the role of a user
+func UpdateUserRole(c *gin.Context) {
+	userID := c.Param("id")
+
+	// Get acting user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	actingUser := user.(models.User)
+
+	var req models.UpdateUserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Find target user
+	var targetUser models.User
+	if err := config.DB.First(&targetUser, "id = ?", userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	// Prevent admins from locking themselves out
+	if targetUser.ID == actingUser.ID && req.Role != models.RoleAdmin {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot remove your own admin role"})
+		return
+	}
+
+	previousRole := targetUser.Role
+	if err := config.DB.Model(&targetUser).Update("role", req.Role).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update role"})
+		return
+	}
+
+	recordAdminEvent(c, models.AuditActionAdminRoleChange, targetUser.ID, previousRole+" -> "+req.Role)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Role updated successfully",
+		"user":        convertUserToAdminResponse(targetUser),
+		"permissions": models.RolePermissions(targetUser.Role),
+	})
+}
+
+// SuspendUser handles suspending a user, optionally until a given time
+func SuspendUser(c *gin.Context) {
+	userID := c.Param("id")
+
+	// Get acting user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	actingUser := user.(models.User)
+
+	var req models.SuspendUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expires_at must be in the future"})
+		return
+	}
+
+	// Find target user
+	var targetUser models.User
+	if err := config.DB.First(&targetUser, "id = ?", userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if targetUser.ID == actingUser.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot suspend your own account"})
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&targetUser).Updates(map[string]interface{}{
+			"suspended_at":      time.Now(),
+			"suspended_until":   req.ExpiresAt,
+			"suspension_reason": req.Reason,
+		}).Error; err != nil {
+			return err
+		}
+
+		// End all sessions of the suspended user
+		return revokeUserSessions(tx, targetUser.ID, "")
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to suspend user"})
+		return
+	}
+
+	recordAdminEvent(c, models.AuditActionAdminSuspend, targetUser.ID, req.Reason)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User suspended successfully",
+		"user":    convertUserToAdminResponse(targetUser),
+	})
+}
+
+// UnsuspendUser handles lifting a user's suspension
+func UnsuspendUser(c *gin.Context) {
+	var targetUser models.User
+	if err := config.DB.First(&targetUser, "id = ?", c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if err := config.DB.Model(&targetUser).Updates(map[string]interface{}{
+		"suspended_at":      nil,
+		"suspended_until":   nil,
+		"suspension_reason": "",
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unsuspend user"})
+		return
+	}
+
+	recordAdminEvent(c, models.AuditActionAdminUnsuspend, targetUser.ID, "")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User unsuspended successfully",
+		"user":    convertUserToAdminResponse(targetUser),
+	})
+}
+
+// ForcePasswordReset handles requiring a user to choose a new password
+func ForcePasswordReset(c *gin.Context) {
+	var targetUser models.User
+	if err := config.DB.First(&targetUser, "id = ?", c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&targetUser).Update("password_reset_required", true).Error; err != nil {
+			return err
+		}
+
+		// End all sessions until the password has been reset
+		return revokeUserSessions(tx, targetUser.ID, "")
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to force password reset"})
+		return
+	}
+
+	recordAdminEvent(c, models.AuditActionAdminPasswordReset, targetUser.ID, "")
+
+	if err := sendPasswordResetEmail(targetUser); err != nil {
+		log.Printf("Failed to send password reset email to user %s: %v", targetUser.ID, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Password reset required, a reset link has been sent to the user",
+		"user":    convertUserToAdminResponse(targetUser),
+	})
+}
+
+// DeleteUser handles permanently deleting a user and all of their content
+func DeleteUser(c *gin.Context) {
+	// Get acting user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	actingUser := user.(models.User)
+
+	var targetUser models.User
+	if err := config.DB.First(&targetUser, "id = ?", c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if targetUser.ID == actingUser.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot delete your own account from the admin API"})
+		return
+	}
+
+	if err := config.DB.Transaction(func(tx *gorm.DB) error {
+		return hardDeleteUser(tx, targetUser.ID)
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
+		return
+	}
+
+	recordAdminEvent(c, models.AuditActionAdminDelete, targetUser.ID, targetUser.Username)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User deleted successfully",
+	})
+}
+
+// hardDeleteUser permanently removes a user together with their posts, comments,
+// likes, follows, blocks, and credentials. Comments and likes left by others on the user's posts,
+// and replies to the user's comments, are removed as well.
+func hardDeleteUser(tx *gorm.DB, userID string) error {
+	db := tx.Unscoped()
+
+	// Collect the user's posts
+	var postIDs []string
+	if err := db.Model(&models.Post{}).Where("author_id = ?", userID).Pluck("id", &postIDs).Error; err != nil {
+		return err
+	}
+
+	// Remove likes by the user and on the user's posts
+	likes := db.Where("user_id = ?", userID)
+	if len(postIDs) > 0 {
+		likes = likes.Or("post_id IN ?", postIDs)
+	}
+	if err := likes.Delete(&models.Like{}).Error; err != nil {
+		return err
+	}
+
+	// Collect comments by the user and on the user's posts, then their replies
+	comments := db.Model(&models.Comment{}).Where("author_id = ?", userID)
+	if len(postIDs) > 0 {
+		comments = comments.Or("post_id IN ?", postIDs)
+	}
+	var commentIDs []string
+	if err := comments.Pluck("id", &commentIDs).Error; err != nil {
+		return err
+	}
+
+	parentIDs := commentIDs
+	for len(parentIDs) > 0 {
+		var replyIDs []string
+		if err := db.Model(&models.Comment{}).Where("parent_comment_id IN ?", parentIDs).Pluck("id", &replyIDs).Error; err != nil {
+			return err
+		}
+		commentIDs = append(commentIDs, replyIDs...)
+		parentIDs = replyIDs
+	}
+
+	if len(commentIDs) > 0 {
+		if err := db.Where("id IN ?", commentIDs).Delete(&models.Comment{}).Error; err != nil {
+			return err
+		}
+	}
+
+	if len(postIDs) > 0 {
+		if err := db.Where("post_id IN ?", postIDs).Delete(&models.PostSlug{}).Error; err != nil {
+			return err
+		}
+		if err := db.Where("post_id IN ?", postIDs).Delete(&models.PostRevision{}).Error; err != nil {
+			return err
+		}
+		if err := db.Where("id IN ?", postIDs).Delete(&models.Post{}).Error; err != nil {
+			return err
+		}
+	}
+
+	// Remove follows and credentials
+	if err := deleteUserFollows(db, userID); err != nil {
+		return err
+	}
+	if err := deleteUserBlocks(db, userID); err != nil {
+		return err
+	}
+	if err := deleteUserCredentials(db, userID); err != nil {
+		return err
+	}
+
+	return db.Where("id = ?", userID).Delete(&models.User{}).Error
+}
+
+// deleteUserCredentials removes every way of signing in as the user
+func deleteUserCredentials(db *gorm.DB, userID string) error {
+	if err := db.Where("user_id = ?", userID).Delete(&models.RefreshToken{}).Error; err != nil {
+		return err
+	}
+	if err := db.Where("user_id = ?", userID).Delete(&models.Session{}).Error; err != nil {
+		return err
+	}
+	if err := db.Where("user_id = ?", userID).Delete(&models.UserToken{}).Error; err != nil {
+		return err
+	}
+	if err := db.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return err
+	}
+	if err := db.Where("user_id = ?", userID).Delete(&models.APIKey{}).Error; err != nil {
+		return err
+	}
+	if err := db.Where("user_id = ?", userID).Delete(&models.OAuthIdentity{}).Error; err != nil {
+		return err
+	}
+	if err := db.Where("user_id = ?", userID).Delete(&models.PasswordHistory{}).Error; err != nil {
+		return err
+	}
+	if err := db.Where("user_id = ?", userID).Delete(&models.WebAuthnCredential{}).Error; err != nil {
+		return err
+	}
+	if err := db.Where("user_id = ?", userID).Delete(&models.WebAuthnChallenge{}).Error; err != nil {
+		return err
+	}
+	return db.Where("user_id = ?", userID).Delete(&models.OAuthState{}).Error
+}
+
+// convertUserToAdminResponse converts a user to the admin response format
+func convertUserToAdminResponse(user models.User) models.AdminUserResponse {
+	return models.AdminUserResponse{
+		ID:                    user.ID,
+		Username:              user.Username,
+		Email:                 user.Email,
+		EmailVerifiedAt:       user.EmailVerifiedAt,
+		Role:                  user.Role,
+		Status:                user.Status(),
+		SuspendedAt:           user.SuspendedAt,
+		SuspendedUntil:        user.SuspendedUntil,
+		SuspensionReason:      user.SuspensionReason,
+		PasswordResetRequired: user.PasswordResetRequired,
+		CreatedAt:             user.CreatedAt,
+		UpdatedAt:             user.UpdatedAt,
+	}
+}
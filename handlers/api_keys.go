@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"blog-api/audit"
+	"blog-api/config"
+	"blog-api/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CreateAPIKey handles creating a personal API key. The raw key is only
+// returned in this response.
+func CreateAPIKey(c *gin.Context) {
+	// Get user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	userModel := user.(models.User)
+
+	var req models.APIKeyCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expires_at must be in the future"})
+		return
+	}
+
+	secret, err := generateOpaqueToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate API key"})
+		return
+	}
+	rawKey := models.APIKeyPrefix + secret
+
+	apiKey := models.APIKey{
+		ID:        uuid.New().String(),
+		UserID:    userModel.ID,
+		Name:      req.Name,
+		Prefix:    rawKey[:len(models.APIKeyPrefix)+8],
+		KeyHash:   hashToken(rawKey),
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+	}
+
+	if err := config.DB.Create(&apiKey).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key"})
+		return
+	}
+
+	audit.Record(c, audit.Event{
+		Action:     models.AuditActionAPIKeyCreate,
+		TargetType: models.AuditTargetAPIKey,
+		TargetID:   apiKey.ID,
+		Reason:     apiKey.Name,
+	})
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "API key created successfully. Copy the key now, it will not be shown again",
+		"api_key": convertAPIKeyToResponse(apiKey),
+		"key":     rawKey,
+	})
+}
+
+// ListAPIKeys handles listing the authenticated user's API keys
+func ListAPIKeys(c *gin.Context) {
+	// Get user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	userModel := user.(models.User)
+
+	var apiKeys []models.APIKey
+	if err := config.DB.Where("user_id = ?", userModel.ID).Order("created_at DESC").Find(&apiKeys).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch API keys"})
+		return
+	}
+
+	// Convert to response format
+	apiKeysResponse := make([]models.APIKeyResponse, 0, len(apiKeys))
+	for _, apiKey := range apiKeys {
+		apiKeysResponse = append(apiKeysResponse, convertAPIKeyToResponse(apiKey))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"api_keys":         apiKeysResponse,
+		"available_scopes": models.APIKeyScopes,
+	})
+}
+
+// RevokeAPIKey handles revoking one of the authenticated user's API keys
+func RevokeAPIKey(c *gin.Context) {
+	// Get user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	userModel := user.(models.User)
+
+	var apiKey models.APIKey
+	if err := config.DB.Where("id = ? AND user_id = ?", c.Param("id"), userModel.ID).First(&apiKey).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+		return
+	}
+
+	if apiKey.RevokedAt == nil {
+		if err := config.DB.Model(&apiKey).Update("revoked_at", time.Now()).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke API key"})
+			return
+		}
+
+		audit.Record(c, audit.Event{
+			Action:     models.AuditActionAPIKeyRevoke,
+			TargetType: models.AuditTargetAPIKey,
+			TargetID:   apiKey.ID,
+			Reason:     apiKey.Name,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "API key revoked successfully",
+	})
+}
+
+// convertAPIKeyToResponse converts an API key to response format
+func convertAPIKeyToResponse(apiKey models.APIKey) models.APIKeyResponse {
+	return models.APIKeyResponse{
+		ID:         apiKey.ID,
+		Name:       apiKey.Name,
+		Prefix:     apiKey.Prefix,
+		Scopes:     apiKey.Scopes,
+		ExpiresAt:  apiKey.ExpiresAt,
+		LastUsedAt: apiKey.LastUsedAt,
+		RevokedAt:  apiKey.RevokedAt,
+		CreatedAt:  apiKey.CreatedAt,
+	}
+}
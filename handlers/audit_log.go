@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"blog-api/audit"
+	"blog-api/config"
+	"blog-api/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// auditLogCSVHeader is the first row of a CSV export
+var auditLogCSVHeader = []string{"id", "created_at", "actor_id", "action", "target_type", "target_id", "outcome", "reason", "ip_address", "user_agent"}
+
+// ListAuditLogs handles listing audit log entries, newest first, with pagination and filters
+func ListAuditLogs(c *gin.Context) {
+	// Get pagination parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+	offset := (page - 1) * limit
+
+	query, ok := filterAuditLogs(c)
+	if !ok {
+		return
+	}
+
+	// Count matching entries
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch audit log"})
+		return
+	}
+
+	entries := []models.AuditLog{}
+	if err := query.Offset(offset).Limit(limit).Order("created_at DESC").Find(&entries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch audit log"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"entries": entries,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+// ExportAuditLogs handles downloading the matching audit log entries, oldest
+// first, as CSV or JSON Lines. The export is streamed row by row.
+func ExportAuditLogs(c *gin.Context) {
+	format := c.DefaultQuery("format", "jsonl")
+	if format != "csv" && format != "jsonl" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format, expected csv or jsonl"})
+		return
+	}
+
+	query, ok := filterAuditLogs(c)
+	if !ok {
+		return
+	}
+
+	rows, err := query.Order("created_at ASC").Rows()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export audit log"})
+		return
+	}
+	defer rows.Close()
+
+	filename := fmt.Sprintf("audit-log-%s.%s", time.Now().UTC().Format("20060102-150405"), format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	if format == "csv" {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+	} else {
+		c.Header("Content-Type", "application/x-ndjson")
+	}
+	c.Status(http.StatusOK)
+
+	csvWriter := csv.NewWriter(c.Writer)
+	jsonEncoder := json.NewEncoder(c.Writer)
+	if format == "csv" {
+		csvWriter.Write(auditLogCSVHeader)
+	}
+
+	for rows.Next() {
+		var entry models.AuditLog
+		if err := config.DB.ScanRows(rows, &entry); err != nil {
+			log.Printf("Failed to read audit log entry during export: %v", err)
+			break
+		}
+
+		if format == "csv" {
+			err = csvWriter.Write(auditLogCSVRecord(entry))
+		} else {
+			err = jsonEncoder.Encode(entry)
+		}
+		if err != nil {
+			// The client went away
+			break
+		}
+	}
+	csvWriter.Flush()
+}
+
+// filterAuditLogs builds the audit log query from the user_id, action,
+// outcome, from, and to query parameters. It responds with 400 and returns
+// false when a parameter is invalid.
+func filterAuditLogs(c *gin.Context) (*gorm.DB, bool) {
+	query := config.DB.Model(&models.AuditLog{})
+
+	// Entries where the user acted or was acted upon
+	if userID := c.Query("user_id"); userID != "" {
+		query = query.Where("actor_id = ? OR (target_type = ? AND target_id = ?)", userID, models.AuditTargetUser, userID)
+	}
+
+	// An action ending in * matches every action with that prefix, e.g. auth.*
+	if action := c.Query("action"); action != "" {
+		if prefix, found := strings.CutSuffix(action, "*"); found {
+			query = query.Where("action LIKE ?", escapeLike(prefix)+"%")
+		} else {
+			query = query.Where("action = ?", action)
+		}
+	}
+
+	if outcome := c.Query("outcome"); outcome != "" {
+		switch outcome {
+		case models.AuditOutcomeSuccess, models.AuditOutcomeFailure, models.AuditOutcomeDenied:
+			query = query.Where("outcome = ?", outcome)
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid outcome, expected success, failure, or denied"})
+			return nil, false
+		}
+	}
+
+	// Filter by time range
+	if from := c.Query("from"); from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from, expected RFC 3339 timestamp"})
+			return nil, false
+		}
+		query = query.Where("created_at >= ?", t)
+	}
+	if to := c.Query("to"); to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to, expected RFC 3339 timestamp"})
+			return nil, false
+		}
+		query = query.Where("created_at < ?", t)
+	}
+
+	return query, true
+}
+
+// auditLogCSVRecord converts an entry to a CSV row matching auditLogCSVHeader
+func auditLogCSVRecord(entry models.AuditLog) []string {
+	actorID := ""
+	if entry.ActorID != nil {
+		actorID = *entry.ActorID
+	}
+
+	return []string{
+		entry.ID,
+		entry.CreatedAt.UTC().Format(time.RFC3339),
+		actorID,
+		entry.Action,
+		entry.TargetType,
+		entry.TargetID,
+		entry.Outcome,
+		entry.Reason,
+		entry.IPAddress,
+		entry.UserAgent,
+	}
+}
+
+// escapeLike escapes the wildcard characters of a LIKE pattern
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// recordAccountEvent records that the user successfully did something to
+// their own account
+func recordAccountEvent(c *gin.Context, action, userID string) {
+	audit.Record(c, audit.Event{
+		Action:     action,
+		ActorID:    userID,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userID,
+	})
+}
+
+// recordAccountFailure records that an attempt on the user's account failed.
+// The actor is the authenticated user, if any, since whoever failed may not
+// be the account owner.
+func recordAccountFailure(c *gin.Context, action, userID, reason string) {
+	audit.Record(c, audit.Event{
+		Action:     action,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userID,
+		Outcome:    models.AuditOutcomeFailure,
+		Reason:     reason,
+	})
+}
+
+// recordAdminEvent records an action the authenticated admin took on a user
+func recordAdminEvent(c *gin.Context, action, targetUserID, reason string) {
+	audit.Record(c, audit.Event{
+		Action:     action,
+		TargetType: models.AuditTargetUser,
+		TargetID:   targetUserID,
+		Reason:     reason,
+	})
+}
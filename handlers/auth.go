@@ -1,17 +1,18 @@
 package handlers
 
 import (
+	"errors"
+	"log"
 	"net/http"
-	"os"
-	"time"
 
+	"blog-api/audit"
 	"blog-api/config"
 	"blog-api/models"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 // Register handles user registration
@@ -22,6 +23,20 @@ func Register(c *gin.Context) {
 		return
 	}
 
+	// Check that registration is open to this email address
+	invite, err := checkRegistration(req.Email, req.InviteCode)
+	if err != nil {
+		audit.Record(c, audit.Event{
+			Action:     models.AuditActionRegister,
+			TargetType: models.AuditTargetEmail,
+			TargetID:   req.Email,
+			Outcome:    models.AuditOutcomeDenied,
+			Reason:     err.Error(),
+		})
+		respondRegistrationError(c, err)
+		return
+	}
+
 	// Check if user already exists
 	var existingUser models.User
 	if err := config.DB.Where("username = ? OR email = ?", req.Username, req.Email).First(&existingUser).Error; err == nil {
@@ -29,6 +44,11 @@ func Register(c *gin.Context) {
 		return
 	}
 
+	// Check the password against the password policy
+	if !validatePassword(c, req.Password, models.User{Username: req.Username, Email: req.Email}) {
+		return
+	}
+
 	// Hash password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -42,32 +62,47 @@ func Register(c *gin.Context) {
 		Username:     req.Username,
 		Email:        req.Email,
 		PasswordHash: string(hashedPassword),
+		Role:         registrationRole(invite),
 	}
 
-	if err := config.DB.Create(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if invite != nil {
+			if err := redeemInvite(tx, invite.ID); err != nil {
+				return err
+			}
+		}
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		return recordPasswordHistory(tx, user.ID, user.PasswordHash)
+	})
+	if err != nil {
+		if !respondRegistrationError(c, err) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+		}
 		return
 	}
 
-	// Generate JWT token
-	token, err := generateToken(user.ID)
+	recordAccountEvent(c, models.AuditActionRegister, user.ID)
+
+	// Send email verification link
+	if err := sendVerificationEmail(user); err != nil {
+		log.Printf("Failed to send verification email to user %s: %v", user.ID, err)
+	}
+
+	// Generate access and refresh tokens
+	tokens, err := issueTokens(c, user.ID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
 
-	// Return success response
-	userResponse := models.UserResponse{
-		ID:        user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		CreatedAt: user.CreatedAt,
-	}
-
 	c.JSON(http.StatusCreated, gin.H{
-		"message": "User registered successfully",
-		"user":    userResponse,
-		"token":   token,
+		"message":       "User registered successfully",
+		"user":          convertUserToProfileResponse(user),
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
 	})
 }
 
@@ -79,38 +114,100 @@ func Login(c *gin.Context) {
 		return
 	}
 
+	// Refuse attempts while the account or client IP is backing off or locked out
+	if checkLoginThrottle(c, req.Email) {
+		audit.Record(c, audit.Event{
+			Action:     models.AuditActionLogin,
+			TargetType: models.AuditTargetEmail,
+			TargetID:   req.Email,
+			Outcome:    models.AuditOutcomeDenied,
+			Reason:     "Too many failed attempts",
+		})
+		return
+	}
+
 	// Find user by email
 	var user models.User
 	if err := config.DB.Where("email = ?", req.Email).First(&user).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+		audit.Record(c, audit.Event{
+			Action:     models.AuditActionLogin,
+			TargetType: models.AuditTargetEmail,
+			TargetID:   req.Email,
+			Outcome:    models.AuditOutcomeFailure,
+			Reason:     "Unknown email",
+		})
+		recordFailedLogin(c, req.Email, nil)
 		return
 	}
 
 	// Check password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+		recordAccountFailure(c, models.AuditActionLogin, user.ID, "Wrong password")
+		recordFailedLogin(c, req.Email, &user)
+		return
+	}
+
+	// Forget earlier failures once the password is correct
+	if err := clearLoginThrottle(req.Email); err != nil {
+		log.Printf("Failed to clear login throttle for user %s: %v", user.ID, err)
+	}
+
+	completeLogin(c, user, models.AuditActionLogin)
+}
+
+// completeLogin finishes a login once the user has proven who they are. It
+// refuses inactive accounts, asks for the second factor when enabled, and
+// otherwise responds with a new token pair. The outcome is recorded in the
+// audit log under the given action.
+func completeLogin(c *gin.Context, user models.User, action string) {
+	event := audit.Event{
+		Action:     action,
+		ActorID:    user.ID,
+		TargetType: models.AuditTargetUser,
+		TargetID:   user.ID,
+	}
+
+	// Check account status
+	if user.IsSuspended() {
+		event.Outcome, event.Reason = models.AuditOutcomeDenied, "Account suspended"
+		audit.Record(c, event)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":           "Account suspended",
+			"reason":          user.SuspensionReason,
+			"suspended_until": user.SuspendedUntil,
+		})
+		return
+	}
+	if user.PasswordResetRequired {
+		event.Outcome, event.Reason = models.AuditOutcomeDenied, "Password reset required"
+		audit.Record(c, event)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Password reset required, check your email for a reset link"})
 		return
 	}
 
-	// Generate JWT token
-	token, err := generateToken(user.ID)
+	// Require the second factor when two-factor authentication is enabled
+	if user.TOTPEnabledAt != nil {
+		event.Reason = "Second factor required"
+		audit.Record(c, event)
+		startTwoFactorChallenge(c, user)
+		return
+	}
+
+	// Generate access and refresh tokens
+	tokens, err := issueTokens(c, user.ID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
 
-	// Return success response
-	userResponse := models.UserResponse{
-		ID:        user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		CreatedAt: user.CreatedAt,
-	}
+	audit.Record(c, event)
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Login successful",
-		"user":    userResponse,
-		"token":   token,
+		"message":       "Login successful",
+		"user":          convertUserToProfileResponse(user),
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
 	})
 }
 
@@ -125,27 +222,76 @@ func GetProfile(c *gin.Context) {
 
 	userModel := user.(models.User)
 
-	// Return user profile
-	userResponse := models.UserResponse{
-		ID:        userModel.ID,
-		Username:  userModel.Username,
-		Email:     userModel.Email,
-		CreatedAt: userModel.CreatedAt,
+	c.JSON(http.StatusOK, gin.H{
+		"user": convertUserToProfileResponse(userModel),
+	})
+}
+
+// RefreshToken handles exchanging a refresh token for a new token pair
+func RefreshToken(c *gin.Context) {
+	var req models.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokens, err := rotateRefreshToken(c, req.RefreshToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, errRefreshTokenInvalid):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+		case errors.Is(err, errRefreshTokenExpired):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token expired"})
+		case errors.Is(err, errRefreshTokenReused):
+			audit.Record(c, audit.Event{
+				Action:  models.AuditActionRefresh,
+				Outcome: models.AuditOutcomeDenied,
+				Reason:  "Refresh token reuse detected, session revoked",
+			})
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token reuse detected, please log in again"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+		}
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"user": userResponse,
+		"message":       "Token refreshed successfully",
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
 	})
 }
 
-// generateToken generates a JWT token for the user
-func generateToken(userID string) (string, error) {
-	// Create token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": userID,
-		"exp":     time.Now().Add(time.Hour * 24 * 30).Unix(), // 30 days
+// Logout handles revoking the refresh token family of the current login
+func Logout(c *gin.Context) {
+	var req models.LogoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Find refresh token
+	var refreshToken models.RefreshToken
+	if err := config.DB.Where("token_hash = ?", hashToken(req.RefreshToken)).First(&refreshToken).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+		return
+	}
+
+	// Revoke every token issued for this login
+	if err := revokeSession(config.DB, refreshToken.FamilyID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+		return
+	}
+
+	audit.Record(c, audit.Event{
+		Action:     models.AuditActionLogout,
+		ActorID:    refreshToken.UserID,
+		TargetType: models.AuditTargetSession,
+		TargetID:   refreshToken.FamilyID,
 	})
 
-	// Sign and get the complete encoded token as a string
-	return token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Logged out successfully",
+	})
 }
@@ -0,0 +1,318 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"blog-api/config"
+	"blog-api/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// BlockUser handles blocking another user. Follows between the two users are removed.
+func BlockUser(c *gin.Context) {
+	// Get user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	userModel := user.(models.User)
+
+	target, ok := findTargetUser(c)
+	if !ok {
+		return
+	}
+
+	if target.ID == userModel.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot block yourself"})
+		return
+	}
+
+	var count int64
+	config.DB.Model(&models.Block{}).Where("blocker_id = ? AND blocked_id = ?", userModel.ID, target.ID).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "You have already blocked this user"})
+		return
+	}
+
+	block := models.Block{
+		ID:        uuid.New().String(),
+		BlockerID: userModel.ID,
+		BlockedID: target.ID,
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&block).Error; err != nil {
+			return err
+		}
+		return tx.Where("(follower_id = ? AND following_id = ?) OR (follower_id = ? AND following_id = ?)",
+			userModel.ID, target.ID, target.ID, userModel.ID).Delete(&models.Follow{}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to block user"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "User blocked successfully",
+		"block": models.BlockResponse{
+			User:      convertUserToResponse(target),
+			BlockedAt: block.CreatedAt,
+		},
+	})
+}
+
+// UnblockUser handles unblocking a user
+func UnblockUser(c *gin.Context) {
+	// Get user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	userModel := user.(models.User)
+
+	target, ok := findTargetUser(c)
+	if !ok {
+		return
+	}
+
+	result := config.DB.Where("blocker_id = ? AND blocked_id = ?", userModel.ID, target.ID).Delete(&models.Block{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unblock user"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "You have not blocked this user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User unblocked successfully",
+	})
+}
+
+// ListBlocks handles listing the users the authenticated user has blocked
+func ListBlocks(c *gin.Context) {
+	// Get user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	userModel := user.(models.User)
+
+	// Get pagination parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset := (page - 1) * limit
+
+	var blocks []models.Block
+	var total int64
+
+	config.DB.Model(&models.Block{}).Where("blocker_id = ?", userModel.ID).Count(&total)
+
+	if err := config.DB.Preload("Blocked").
+		Where("blocker_id = ?", userModel.ID).
+		Offset(offset).
+		Limit(limit).
+		Order("created_at DESC").
+		Find(&blocks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch blocked users"})
+		return
+	}
+
+	// Convert to response format
+	var blocksResponse []models.BlockResponse
+	for _, block := range blocks {
+		blocksResponse = append(blocksResponse, models.BlockResponse{
+			User:      convertUserToResponse(block.Blocked),
+			BlockedAt: block.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"blocks": blocksResponse,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+// MuteUser handles muting another user
+func MuteUser(c *gin.Context) {
+	// Get user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	userModel := user.(models.User)
+
+	target, ok := findTargetUser(c)
+	if !ok {
+		return
+	}
+
+	if target.ID == userModel.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot mute yourself"})
+		return
+	}
+
+	var count int64
+	config.DB.Model(&models.Mute{}).Where("muter_id = ? AND muted_id = ?", userModel.ID, target.ID).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "You have already muted this user"})
+		return
+	}
+
+	mute := models.Mute{
+		ID:      uuid.New().String(),
+		MuterID: userModel.ID,
+		MutedID: target.ID,
+	}
+
+	if err := config.DB.Create(&mute).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mute user"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "User muted successfully",
+		"mute": models.MuteResponse{
+			User:    convertUserToResponse(target),
+			MutedAt: mute.CreatedAt,
+		},
+	})
+}
+
+// UnmuteUser handles unmuting a user
+func UnmuteUser(c *gin.Context) {
+	// Get user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	userModel := user.(models.User)
+
+	target, ok := findTargetUser(c)
+	if !ok {
+		return
+	}
+
+	result := config.DB.Where("muter_id = ? AND muted_id = ?", userModel.ID, target.ID).Delete(&models.Mute{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unmute user"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "You have not muted this user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User unmuted successfully",
+	})
+}
+
+// ListMutes handles listing the users the authenticated user has muted
+func ListMutes(c *gin.Context) {
+	// Get user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	userModel := user.(models.User)
+
+	// Get pagination parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset := (page - 1) * limit
+
+	var mutes []models.Mute
+	var total int64
+
+	config.DB.Model(&models.Mute{}).Where("muter_id = ?", userModel.ID).Count(&total)
+
+	if err := config.DB.Preload("Muted").
+		Where("muter_id = ?", userModel.ID).
+		Offset(offset).
+		Limit(limit).
+		Order("created_at DESC").
+		Find(&mutes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch muted users"})
+		return
+	}
+
+	// Convert to response format
+	var mutesResponse []models.MuteResponse
+	for _, mute := range mutes {
+		mutesResponse = append(mutesResponse, models.MuteResponse{
+			User:    convertUserToResponse(mute.Muted),
+			MutedAt: mute.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"mutes": mutesResponse,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+// isBlockedBetween reports whether either user has blocked the other
+func isBlockedBetween(userID, otherID string) bool {
+	var count int64
+	config.DB.Model(&models.Block{}).
+		Where("(blocker_id = ? AND blocked_id = ?) OR (blocker_id = ? AND blocked_id = ?)", userID, otherID, otherID, userID).
+		Count(&count)
+	return count > 0
+}
+
+// hiddenAuthorIDs returns the users whose content is hidden from the signed-in
+// viewer: everyone they have muted or blocked. It returns nil for anonymous requests.
+func hiddenAuthorIDs(c *gin.Context) []string {
+	user, exists := c.Get("user")
+	if !exists {
+		return nil
+	}
+	userID := user.(models.User).ID
+
+	var muted, blocked []string
+	config.DB.Model(&models.Mute{}).Where("muter_id = ?", userID).Pluck("muted_id", &muted)
+	config.DB.Model(&models.Block{}).Where("blocker_id = ?", userID).Pluck("blocked_id", &blocked)
+	return append(muted, blocked...)
+}
+
+// excludeAuthors limits a query to rows whose author is not in authorIDs
+func excludeAuthors(db *gorm.DB, authorIDs []string) *gorm.DB {
+	if len(authorIDs) == 0 {
+		return db
+	}
+	return db.Where("author_id NOT IN ?", authorIDs)
+}
+
+// deleteUserBlocks removes the blocks and mutes from and to the user
+func deleteUserBlocks(db *gorm.DB, userID string) error {
+	if err := db.Where("blocker_id = ? OR blocked_id = ?", userID, userID).Delete(&models.Block{}).Error; err != nil {
+		return err
+	}
+	return db.Where("muter_id = ? OR muted_id = ?", userID, userID).Delete(&models.Mute{}).Error
+}
@@ -26,11 +26,16 @@ func CreateComment(c *gin.Context) {
 
 	// Check if post exists
 	var post models.Post
-	if err := config.DB.First(&post, "id = ?", postID).Error; err != nil {
+	if err := config.DB.First(&post, "id = ?", postID).Error; err != nil || !canViewPost(c, post) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
 		return
 	}
 
+	if isBlockedBetween(userModel.ID, post.AuthorID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You cannot comment on this user's posts"})
+		return
+	}
+
 	// Parse comment request
 	var req models.CommentCreateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -60,14 +65,9 @@ func CreateComment(c *gin.Context) {
 		AuthorID:        comment.AuthorID,
 		ParentCommentID: comment.ParentCommentID,
 		Content:         comment.Content,
-		Author: models.UserResponse{
-			ID:        comment.Author.ID,
-			Username:  comment.Author.Username,
-			Email:     comment.Author.Email,
-			CreatedAt: comment.Author.CreatedAt,
-		},
-		CreatedAt: comment.CreatedAt,
-		UpdatedAt: comment.UpdatedAt,
+		Author:          convertUserToResponse(comment.Author),
+		CreatedAt:       comment.CreatedAt,
+		UpdatedAt:       comment.UpdatedAt,
 	}
 
 	c.JSON(http.StatusCreated, gin.H{
@@ -96,6 +96,17 @@ func ReplyToComment(c *gin.Context) {
 		return
 	}
 
+	// Blocks by the comment author and by the post author both apply
+	var post models.Post
+	if err := config.DB.First(&post, "id = ?", parentComment.PostID).Error; err != nil || !canViewPost(c, post) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		return
+	}
+	if isBlockedBetween(userModel.ID, parentComment.AuthorID) || isBlockedBetween(userModel.ID, post.AuthorID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You cannot reply to this user"})
+		return
+	}
+
 	// Parse reply request
 	var req models.CommentReplyRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -126,14 +137,9 @@ func ReplyToComment(c *gin.Context) {
 		AuthorID:        comment.AuthorID,
 		ParentCommentID: comment.ParentCommentID,
 		Content:         comment.Content,
-		Author: models.UserResponse{
-			ID:        comment.Author.ID,
-			Username:  comment.Author.Username,
-			Email:     comment.Author.Email,
-			CreatedAt: comment.Author.CreatedAt,
-		},
-		CreatedAt: comment.CreatedAt,
-		UpdatedAt: comment.UpdatedAt,
+		Author:          convertUserToResponse(comment.Author),
+		CreatedAt:       comment.CreatedAt,
+		UpdatedAt:       comment.UpdatedAt,
 	}
 
 	c.JSON(http.StatusCreated, gin.H{
@@ -148,18 +154,21 @@ func GetComments(c *gin.Context) {
 
 	// Check if post exists
 	var post models.Post
-	if err := config.DB.First(&post, "id = ?", postID).Error; err != nil {
+	if err := config.DB.First(&post, "id = ?", postID).Error; err != nil || !canViewPost(c, post) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
 		return
 	}
 
+	// Leave out comments by muted and blocked users
+	hidden := hiddenAuthorIDs(c)
+
 	// Get comments with nested replies
 	var comments []models.Comment
-	if err := config.DB.Preload("Author").
+	if err := excludeAuthors(config.DB.Preload("Author").
 		Preload("Replies", func(db *gorm.DB) *gorm.DB {
-			return db.Preload("Author")
+			return excludeAuthors(db.Preload("Author"), hidden)
 		}).
-		Where("post_id = ? AND parent_comment_id IS NULL", postID).
+		Where("post_id = ? AND parent_comment_id IS NULL", postID), hidden).
 		Order("created_at ASC").
 		Find(&comments).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch comments"})
@@ -198,8 +207,8 @@ func UpdateComment(c *gin.Context) {
 		return
 	}
 
-	// Check if user is the author
-	if comment.AuthorID != userModel.ID {
+	// Check if user is the author or may edit any comment
+	if comment.AuthorID != userModel.ID && !userModel.HasPermission(models.PermissionEditAnyComment) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "You can only update your own comments"})
 		return
 	}
@@ -227,14 +236,9 @@ func UpdateComment(c *gin.Context) {
 		AuthorID:        comment.AuthorID,
 		ParentCommentID: comment.ParentCommentID,
 		Content:         comment.Content,
-		Author: models.UserResponse{
-			ID:        comment.Author.ID,
-			Username:  comment.Author.Username,
-			Email:     comment.Author.Email,
-			CreatedAt: comment.Author.CreatedAt,
-		},
-		CreatedAt: comment.CreatedAt,
-		UpdatedAt: comment.UpdatedAt,
+		Author:          convertUserToResponse(comment.Author),
+		CreatedAt:       comment.CreatedAt,
+		UpdatedAt:       comment.UpdatedAt,
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -263,8 +267,8 @@ func DeleteComment(c *gin.Context) {
 		return
 	}
 
-	// Check if user is the author
-	if comment.AuthorID != userModel.ID {
+	// Check if user is the author or may delete any comment
+	if comment.AuthorID != userModel.ID && !userModel.HasPermission(models.PermissionDeleteAnyComment) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "You can only delete your own comments"})
 		return
 	}
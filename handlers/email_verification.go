@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"blog-api/audit"
+	"blog-api/config"
+	"blog-api/mail"
+	"blog-api/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// VerifyEmail handles confirming an email address with a verification token.
+// The token is read from the "token" query parameter on GET (the emailed link)
+// or from the JSON body on POST.
+func VerifyEmail(c *gin.Context) {
+	rawToken := c.Query("token")
+	if c.Request.Method == http.MethodPost {
+		var req models.VerifyEmailRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		rawToken = req.Token
+	}
+
+	if rawToken == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Verification token required"})
+		return
+	}
+
+	var userToken *models.UserToken
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		userToken, err = consumeUserToken(tx, rawToken, models.TokenPurposeEmailVerification, models.TokenPurposeEmailChange)
+		if err != nil {
+			return err
+		}
+
+		if userToken.Purpose == models.TokenPurposeEmailChange {
+			return confirmEmailChange(tx, userToken.UserID)
+		}
+
+		return tx.Model(&models.User{}).
+			Where("id = ? AND email_verified_at IS NULL", userToken.UserID).
+			Update("email_verified_at", time.Now()).Error
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, errUserTokenInvalid):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired verification token"})
+		case errors.Is(err, errEmailTaken):
+			c.JSON(http.StatusConflict, gin.H{"error": "Email address is already in use"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify email"})
+		}
+		return
+	}
+
+	audit.Record(c, audit.Event{
+		Action:     models.AuditActionEmailVerify,
+		ActorID:    userToken.UserID,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userToken.UserID,
+		Reason:     userToken.Purpose,
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Email verified successfully",
+	})
+}
+
+// ResendVerificationEmail handles sending a new verification link
+func ResendVerificationEmail(c *gin.Context) {
+	var req models.ResendVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Respond identically for unknown and already verified addresses
+	response := gin.H{
+		"message": "If the address belongs to an unverified account, a verification link has been sent",
+	}
+
+	var user models.User
+	if err := config.DB.Where("email = ?", req.Email).First(&user).Error; err != nil || user.EmailVerifiedAt != nil {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	if err := sendVerificationEmail(user); err != nil {
+		log.Printf("Failed to send verification email to user %s: %v", user.ID, err)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// confirmEmailChange replaces the user's email address with their pending address
+func confirmEmailChange(tx *gorm.DB, userID string) error {
+	var user models.User
+	if err := tx.First(&user, "id = ?", userID).Error; err != nil {
+		return err
+	}
+
+	if user.PendingEmail == nil {
+		return errUserTokenInvalid
+	}
+
+	var count int64
+	if err := tx.Model(&models.User{}).Where("email = ? AND id <> ?", *user.PendingEmail, user.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errEmailTaken
+	}
+
+	return tx.Model(&user).Updates(map[string]interface{}{
+		"email":             *user.PendingEmail,
+		"pending_email":     nil,
+		"email_verified_at": time.Now(),
+	}).Error
+}
+
+// sendEmailChangeVerification emails a confirmation link to the user's new address
+// and lets the current address know that a change was requested
+func sendEmailChangeVerification(user models.User, newEmail string) error {
+	rawToken, err := createUserToken(config.DB, user.ID, models.TokenPurposeEmailChange, config.EmailVerificationTokenTTL())
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/api/v1/auth/verify-email?token=%s", config.AppURL(), url.QueryEscape(rawToken))
+	if err := mail.Send(mail.Message{
+		To:      newEmail,
+		Subject: "Confirm your new email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your new email address by opening the link below. It expires in %s.\n\n%s\n",
+			user.Username, config.EmailVerificationTokenTTL(), link),
+	}); err != nil {
+		return err
+	}
+
+	return mail.Send(mail.Message{
+		To:      user.Email,
+		Subject: "Your email address is being changed",
+		Body: fmt.Sprintf("Hi %s,\n\nA request was made to change the email address of your account to %s. The change takes effect once the new address is confirmed.\n\nIf you did not make this request, please reset your password.\n",
+			user.Username, newEmail),
+	})
+}
+
+// sendVerificationEmail issues a verification token for the user and emails the link
+func sendVerificationEmail(user models.User) error {
+	rawToken, err := createUserToken(config.DB, user.ID, models.TokenPurposeEmailVerification, config.EmailVerificationTokenTTL())
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/api/v1/auth/verify-email?token=%s", config.AppURL(), url.QueryEscape(rawToken))
+	return mail.Send(mail.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening the link below. It expires in %s.\n\n%s\n",
+			user.Username, config.EmailVerificationTokenTTL(), link),
+	})
+}
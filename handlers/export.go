@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"blog-api/config"
+	"blog-api/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ExportProfile handles downloading a zip archive of the authenticated user's
+// profile, posts, comments, and likes as JSON and as Markdown
+func ExportProfile(c *gin.Context) {
+	// Get user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	userModel := user.(models.User)
+
+	export, err := buildAccountExport(userModel)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export data"})
+		return
+	}
+
+	archive, err := writeExportArchive(export)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export data"})
+		return
+	}
+
+	recordAccountEvent(c, models.AuditActionExport, userModel.ID)
+
+	filename := fmt.Sprintf("blog-export-%s-%s.zip", userModel.Username, export.ExportedAt.Format("2006-01-02"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, "application/zip", archive)
+}
+
+// buildAccountExport collects everything the user has contributed
+func buildAccountExport(user models.User) (*models.AccountExport, error) {
+	export := &models.AccountExport{
+		ExportedAt: time.Now().UTC(),
+		Profile:    convertUserToProfileResponse(user),
+		Posts:      []models.ExportPost{},
+		Comments:   []models.ExportComment{},
+		Likes:      []models.ExportLike{},
+	}
+
+	// Comments and likes keep the title of posts that were deleted since
+	withDeletedPosts := func(db *gorm.DB) *gorm.DB { return db.Unscoped() }
+
+	var posts []models.Post
+	if err := config.DB.Where("author_id = ?", user.ID).Order("created_at ASC").Find(&posts).Error; err != nil {
+		return nil, err
+	}
+	for _, post := range posts {
+		export.Posts = append(export.Posts, models.ExportPost{
+			ID:          post.ID,
+			Title:       post.Title,
+			Content:     post.Content,
+			Tags:        post.Tags,
+			Status:      post.Status,
+			PublishedAt: post.PublishedAt,
+			CreatedAt:   post.CreatedAt,
+			UpdatedAt:   post.UpdatedAt,
+		})
+	}
+
+	var comments []models.Comment
+	if err := config.DB.Preload("Post", withDeletedPosts).
+		Where("author_id = ?", user.ID).Order("created_at ASC").Find(&comments).Error; err != nil {
+		return nil, err
+	}
+	for _, comment := range comments {
+		export.Comments = append(export.Comments, models.ExportComment{
+			ID:              comment.ID,
+			PostID:          comment.PostID,
+			PostTitle:       comment.Post.Title,
+			ParentCommentID: comment.ParentCommentID,
+			Content:         comment.Content,
+			CreatedAt:       comment.CreatedAt,
+			UpdatedAt:       comment.UpdatedAt,
+		})
+	}
+
+	var likes []models.Like
+	if err := config.DB.Preload("Post", withDeletedPosts).
+		Where("user_id = ?", user.ID).Order("created_at ASC").Find(&likes).Error; err != nil {
+		return nil, err
+	}
+	for _, like := range likes {
+		export.Likes = append(export.Likes, models.ExportLike{
+			PostID:    like.PostID,
+			PostTitle: like.Post.Title,
+			CreatedAt: like.CreatedAt,
+		})
+	}
+
+	return export, nil
+}
+
+// writeExportArchive packs the export into a zip with account.json and a
+// Markdown rendering of each part
+func writeExportArchive(export *models.AccountExport) ([]byte, error) {
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	type exportFile struct {
+		name    string
+		content []byte
+	}
+
+	files := []exportFile{
+		{"account.json", data},
+		{"profile.md", []byte(renderProfileMarkdown(export))},
+		{"comments.md", []byte(renderCommentsMarkdown(export.Comments))},
+		{"likes.md", []byte(renderLikesMarkdown(export.Likes))},
+	}
+	for _, post := range export.Posts {
+		files = append(files, exportFile{
+			name:    fmt.Sprintf("posts/%s-%s.md", post.CreatedAt.Format("2006-01-02"), post.ID),
+			content: []byte(renderPostMarkdown(post)),
+		})
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, file := range files {
+		w, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     file.name,
+			Method:   zip.Deflate,
+			Modified: export.ExportedAt,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(file.content); err != nil {
+			return nil, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// renderProfileMarkdown renders the profile and a summary of the archive
+func renderProfileMarkdown(export *models.AccountExport) string {
+	profile := export.Profile
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", profile.Username)
+	fmt.Fprintf(&b, "- Email: %s\n", profile.Email)
+	if profile.DisplayName != "" {
+		fmt.Fprintf(&b, "- Display name: %s\n", profile.DisplayName)
+	}
+	if profile.Website != "" {
+		fmt.Fprintf(&b, "- Website: %s\n", profile.Website)
+	}
+	if profile.AvatarURL != "" {
+		fmt.Fprintf(&b, "- Avatar: %s\n", profile.AvatarURL)
+	}
+	fmt.Fprintf(&b, "- Role: %s\n", profile.Role)
+	fmt.Fprintf(&b, "- Member since: %s\n", profile.CreatedAt.Format(time.RFC3339))
+	fmt.Fprintf(&b, "- Exported at: %s\n", export.ExportedAt.Format(time.RFC3339))
+	if profile.Bio != "" {
+		fmt.Fprintf(&b, "\n## Bio\n\n%s\n", profile.Bio)
+	}
+
+	fmt.Fprintf(&b, "\n## Contents\n\n")
+	fmt.Fprintf(&b, "- %d posts in `posts/`\n", len(export.Posts))
+	fmt.Fprintf(&b, "- %d comments in `comments.md`\n", len(export.Comments))
+	fmt.Fprintf(&b, "- %d likes in `likes.md`\n", len(export.Likes))
+	fmt.Fprintf(&b, "- Everything above as JSON in `account.json`\n")
+
+	return b.String()
+}
+
+// renderPostMarkdown renders a post as a Markdown document
+func renderPostMarkdown(post models.ExportPost) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", post.Title)
+	fmt.Fprintf(&b, "- Status: %s\n", post.Status)
+	if post.PublishedAt != nil {
+		fmt.Fprintf(&b, "- Published: %s\n", post.PublishedAt.Format(time.RFC3339))
+	}
+	fmt.Fprintf(&b, "- Created: %s\n", post.CreatedAt.Format(time.RFC3339))
+	fmt.Fprintf(&b, "- Updated: %s\n", post.UpdatedAt.Format(time.RFC3339))
+	if len(post.Tags) > 0 {
+		fmt.Fprintf(&b, "- Tags: %s\n", strings.Join(post.Tags, ", "))
+	}
+	fmt.Fprintf(&b, "\n%s\n", post.Content)
+	return b.String()
+}
+
+// renderCommentsMarkdown renders all comments, oldest first
+func renderCommentsMarkdown(comments []models.ExportComment) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Comments\n")
+	if len(comments) == 0 {
+		fmt.Fprintf(&b, "\nNo comments.\n")
+	}
+	for _, comment := range comments {
+		kind := "Comment"
+		if comment.ParentCommentID != nil {
+			kind = "Reply"
+		}
+		fmt.Fprintf(&b, "\n## %s on \"%s\"\n\n", kind, comment.PostTitle)
+		fmt.Fprintf(&b, "_%s_\n\n", comment.CreatedAt.Format(time.RFC3339))
+		fmt.Fprintf(&b, "> %s\n", strings.ReplaceAll(comment.Content, "\n", "\n> "))
+	}
+	return b.String()
+}
+
+// renderLikesMarkdown renders the liked posts, oldest first
+func renderLikesMarkdown(likes []models.ExportLike) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Likes\n\n")
+	if len(likes) == 0 {
+		fmt.Fprintf(&b, "No likes.\n")
+	}
+	for _, like := range likes {
+		fmt.Fprintf(&b, "- %s: \"%s\"\n", like.CreatedAt.Format(time.RFC3339), like.PostTitle)
+	}
+	return b.String()
+}
@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"blog-api/config"
+	"blog-api/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// FollowUser handles following another user
+func FollowUser(c *gin.Context) {
+	// Get user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	userModel := user.(models.User)
+
+	target, ok := findTargetUser(c)
+	if !ok {
+		return
+	}
+
+	if target.ID == userModel.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot follow yourself"})
+		return
+	}
+
+	if isBlockedBetween(userModel.ID, target.ID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You cannot follow this user"})
+		return
+	}
+
+	// Check if user already follows this user
+	if isFollowing(userModel.ID, target.ID) {
+		c.JSON(http.StatusConflict, gin.H{"error": "You are already following this user"})
+		return
+	}
+
+	follow := models.Follow{
+		ID:          uuid.New().String(),
+		FollowerID:  userModel.ID,
+		FollowingID: target.ID,
+	}
+
+	if err := config.DB.Create(&follow).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to follow user"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "User followed successfully",
+		"follow": models.FollowResponse{
+			User:       convertUserToResponse(target),
+			FollowedAt: follow.CreatedAt,
+		},
+	})
+}
+
+// UnfollowUser handles unfollowing a user
+func UnfollowUser(c *gin.Context) {
+	// Get user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	userModel := user.(models.User)
+
+	target, ok := findTargetUser(c)
+	if !ok {
+		return
+	}
+
+	result := config.DB.Where("follower_id = ? AND following_id = ?", userModel.ID, target.ID).Delete(&models.Follow{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unfollow user"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "You are not following this user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User unfollowed successfully",
+	})
+}
+
+// GetFollowers handles listing the users who follow a user
+func GetFollowers(c *gin.Context) {
+	listFollows(c, "following_id", "Follower")
+}
+
+// GetFollowing handles listing the users a user follows
+func GetFollowing(c *gin.Context) {
+	listFollows(c, "follower_id", "Following")
+}
+
+// GetFeed handles getting posts from the users the authenticated user follows
+func GetFeed(c *gin.Context) {
+	// Get user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	userModel := user.(models.User)
+
+	// Get pagination parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	offset := (page - 1) * limit
+
+	followed := config.DB.Model(&models.Follow{}).Select("following_id").Where("follower_id = ?", userModel.ID)
+
+	var posts []models.Post
+	var total int64
+
+	// Leave out muted and blocked users
+	hidden := hiddenAuthorIDs(c)
+
+	// Count published posts by followed users
+	excludeAuthors(publishedPosts(config.DB.Model(&models.Post{})).Where("author_id IN (?)", followed), hidden).Count(&total)
+
+	if err := excludeAuthors(publishedPosts(config.DB.Preload("Author")).
+		Preload("Likes").
+		Where("author_id IN (?)", followed), hidden).
+		Offset(offset).
+		Limit(limit).
+		Order("published_at DESC").
+		Find(&posts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch feed"})
+		return
+	}
+
+	// Convert to response format
+	var postsResponse []models.PostResponse
+	for _, post := range posts {
+		postsResponse = append(postsResponse, convertPostToResponse(post))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"posts": postsResponse,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+// listFollows responds with a page of the follows of the user named in the URL.
+// column selects that user's side of the follow and relation the other side.
+func listFollows(c *gin.Context, column, relation string) {
+	target, ok := findTargetUser(c)
+	if !ok {
+		return
+	}
+
+	// Get pagination parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset := (page - 1) * limit
+
+	var follows []models.Follow
+	var total int64
+
+	config.DB.Model(&models.Follow{}).Where(column+" = ?", target.ID).Count(&total)
+
+	if err := config.DB.Preload(relation).
+		Where(column+" = ?", target.ID).
+		Offset(offset).
+		Limit(limit).
+		Order("created_at DESC").
+		Find(&follows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+		return
+	}
+
+	// Convert to response format
+	var usersResponse []models.FollowResponse
+	for _, follow := range follows {
+		other := follow.Follower
+		if relation == "Following" {
+			other = follow.Following
+		}
+		usersResponse = append(usersResponse, models.FollowResponse{
+			User:       convertUserToResponse(other),
+			FollowedAt: follow.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"users": usersResponse,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+// findTargetUser loads the user named in the URL, responding with 404 when
+// there is no such user. It reports whether the user was found.
+func findTargetUser(c *gin.Context) (models.User, bool) {
+	var user models.User
+	if err := config.DB.Where("username = ? AND anonymized_at IS NULL", c.Param("username")).First(&user).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return user, false
+	}
+	return user, true
+}
+
+// isFollowing reports whether followerID follows followingID
+func isFollowing(followerID, followingID string) bool {
+	var count int64
+	config.DB.Model(&models.Follow{}).Where("follower_id = ? AND following_id = ?", followerID, followingID).Count(&count)
+	return count > 0
+}
+
+// deleteUserFollows removes the follows from and to the user
+func deleteUserFollows(db *gorm.DB, userID string) error {
+	return db.Where("follower_id = ? OR following_id = ?", userID, userID).Delete(&models.Follow{}).Error
+}
@@ -0,0 +1,15 @@
+package handlers
+
+import (
+	"net/http"
+
+	"blog-api/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetJWKS handles publishing the public keys that verify access tokens
+func GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, auth.PublicJWKS())
+}
@@ -25,11 +25,16 @@ func LikePost(c *gin.Context) {
 
 	// Check if post exists
 	var post models.Post
-	if err := config.DB.First(&post, "id = ?", postID).Error; err != nil {
+	if err := config.DB.First(&post, "id = ?", postID).Error; err != nil || !canViewPost(c, post) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
 		return
 	}
 
+	if isBlockedBetween(userModel.ID, post.AuthorID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You cannot like this user's posts"})
+		return
+	}
+
 	// Check if user already liked this post
 	var existingLike models.Like
 	if err := config.DB.Where("post_id = ? AND user_id = ?", postID, userModel.ID).First(&existingLike).Error; err == nil {
@@ -56,12 +61,7 @@ func LikePost(c *gin.Context) {
 		ID:        like.ID,
 		PostID:    like.PostID,
 		UserID:    like.UserID,
-		User: models.UserResponse{
-			ID:        like.User.ID,
-			Username:  like.User.Username,
-			Email:     like.User.Email,
-			CreatedAt: like.User.CreatedAt,
-		},
+		User:      convertUserToResponse(like.User),
 		CreatedAt: like.CreatedAt,
 	}
 
@@ -115,7 +115,7 @@ func GetPostLikes(c *gin.Context) {
 
 	// Check if post exists
 	var post models.Post
-	if err := config.DB.First(&post, "id = ?", postID).Error; err != nil {
+	if err := config.DB.First(&post, "id = ?", postID).Error; err != nil || !canViewPost(c, post) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
 		return
 	}
@@ -134,12 +134,7 @@ func GetPostLikes(c *gin.Context) {
 			ID:        like.ID,
 			PostID:    like.PostID,
 			UserID:    like.UserID,
-			User: models.UserResponse{
-				ID:        like.User.ID,
-				Username:  like.User.Username,
-				Email:     like.User.Email,
-				CreatedAt: like.User.CreatedAt,
-			},
+			User:      convertUserToResponse(like.User),
 			CreatedAt: like.CreatedAt,
 		}
 		likesResponse = append(likesResponse, likeResponse)
@@ -174,8 +169,8 @@ func CheckUserLike(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"liked": true,
-		"like_id": like.ID,
+		"liked":    true,
+		"like_id":  like.ID,
 		"liked_at": like.CreatedAt,
 	})
 }
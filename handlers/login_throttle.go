@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"blog-api/config"
+	"blog-api/mail"
+	"blog-api/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UnlockAccount handles lifting a lockout with the token from a lockout email
+func UnlockAccount(c *gin.Context) {
+	var req models.UnlockAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userToken, err := consumeUserToken(config.DB, req.Token, models.TokenPurposeAccountUnlock)
+	if err != nil {
+		if errors.Is(err, errUserTokenInvalid) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired unlock token"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock account"})
+		return
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, "id = ?", userToken.UserID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired unlock token"})
+		return
+	}
+
+	if err := clearLoginThrottle(user.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock account"})
+		return
+	}
+
+	recordAccountEvent(c, models.AuditActionAccountUnlock, user.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Account unlocked successfully",
+	})
+}
+
+// UnlockUser handles an admin lifting the login lockout of a user
+func UnlockUser(c *gin.Context) {
+	var targetUser models.User
+	if err := config.DB.First(&targetUser, "id = ?", c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if err := clearLoginThrottle(targetUser.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock user"})
+		return
+	}
+
+	recordAdminEvent(c, models.AuditActionAdminUnlock, targetUser.ID, "")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User unlocked successfully",
+	})
+}
+
+// checkLoginThrottle responds with 429 and returns true when the account or the
+// client IP must wait before trying to log in again
+func checkLoginThrottle(c *gin.Context, email string) bool {
+	now := time.Now()
+
+	var throttles []models.LoginThrottle
+	if err := config.DB.Where("id IN ?", []string{accountThrottleKey(email), ipThrottleKey(c.ClientIP())}).
+		Find(&throttles).Error; err != nil {
+		return false
+	}
+
+	var retryAfter time.Duration
+	locked := false
+	for _, throttle := range throttles {
+		// Failures older than the lockout window no longer count
+		if !throttle.IsLocked(now) && now.Sub(throttle.LastFailureAt) > config.LoginLockoutDuration() {
+			continue
+		}
+
+		if wait := throttle.RetryAfter(now, config.LoginBackoffBase(), config.LoginLockoutDuration()); wait > retryAfter {
+			retryAfter = wait
+		}
+		if throttle.IsLocked(now) {
+			locked = true
+		}
+	}
+
+	if retryAfter <= 0 {
+		return false
+	}
+
+	respondLoginThrottled(c, retryAfter, locked)
+	return true
+}
+
+// recordFailedLogin counts a failed login against the account and the client IP
+// and responds with 401. When the failure locks the account, the response says
+// so and the owner, if the account exists, is emailed an unlock link.
+func recordFailedLogin(c *gin.Context, email string, user *models.User) {
+	accountThrottle, err := incrementLoginThrottle(accountThrottleKey(email), config.LoginMaxAccountFailures())
+	if err != nil {
+		log.Printf("Failed to record failed login: %v", err)
+	}
+	if _, err := incrementLoginThrottle(ipThrottleKey(c.ClientIP()), config.LoginMaxIPFailures()); err != nil {
+		log.Printf("Failed to record failed login: %v", err)
+	}
+
+	if accountThrottle != nil && accountThrottle.Failures == config.LoginMaxAccountFailures() {
+		if user != nil {
+			if err := sendAccountLockedEmail(*user); err != nil {
+				log.Printf("Failed to send account locked email to user %s: %v", user.ID, err)
+			}
+		}
+
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":        "Invalid email or password",
+			"locked":       true,
+			"locked_until": accountThrottle.LockedUntil,
+			"message":      "Too many failed attempts, the account is temporarily locked",
+		})
+		return
+	}
+
+	c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+}
+
+// clearLoginThrottle forgets the failed logins of an account
+func clearLoginThrottle(email string) error {
+	return config.DB.Where("id = ?", accountThrottleKey(email)).Delete(&models.LoginThrottle{}).Error
+}
+
+// incrementLoginThrottle adds a failure for the subject and locks it once maxFailures is reached
+func incrementLoginThrottle(key string, maxFailures int) (*models.LoginThrottle, error) {
+	now := time.Now()
+
+	var throttle models.LoginThrottle
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&throttle, "id = ?", key).Error
+		if err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			throttle = models.LoginThrottle{ID: key}
+		}
+
+		// Start counting again once the previous failures have aged out
+		if !throttle.IsLocked(now) && now.Sub(throttle.LastFailureAt) > config.LoginLockoutDuration() {
+			throttle.Failures = 0
+			throttle.LockedUntil = nil
+		}
+
+		throttle.Failures++
+		throttle.LastFailureAt = now
+		if throttle.Failures >= maxFailures && !throttle.IsLocked(now) {
+			lockedUntil := now.Add(config.LoginLockoutDuration())
+			throttle.LockedUntil = &lockedUntil
+		}
+
+		return tx.Save(&throttle).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &throttle, nil
+}
+
+// respondLoginThrottled writes the 429 response for a throttled login
+func respondLoginThrottled(c *gin.Context, retryAfter time.Duration, locked bool) {
+	seconds := int64(math.Ceil(retryAfter.Seconds()))
+	c.Header("Retry-After", strconv.FormatInt(seconds, 10))
+
+	message := "Too many failed login attempts, please wait before trying again"
+	if locked {
+		message = "Too many failed login attempts, the account is temporarily locked"
+	}
+
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error":       message,
+		"locked":      locked,
+		"retry_after": seconds,
+	})
+}
+
+// sendAccountLockedEmail tells the user their account was locked and includes a self-service unlock link
+func sendAccountLockedEmail(user models.User) error {
+	rawToken, err := createUserToken(config.DB, user.ID, models.TokenPurposeAccountUnlock, config.LoginLockoutDuration())
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/unlock-account?token=%s", config.AppURL(), url.QueryEscape(rawToken))
+	return mail.Send(mail.Message{
+		To:      user.Email,
+		Subject: "Your account was temporarily locked",
+		Body: fmt.Sprintf("Hi %s,\n\nWe locked your account for %s after several failed sign-in attempts.\n\nIf this was you, use the link below to unlock it right away:\n\n%s\n\nIf it was not you, consider resetting your password.\n",
+			user.Username, config.LoginLockoutDuration(), link),
+	})
+}
+
+// accountThrottleKey returns the throttle key for an email address
+func accountThrottleKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+// ipThrottleKey returns the throttle key for a client IP address
+func ipThrottleKey(ip string) string {
+	return "ip:" + ip
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"blog-api/audit"
+	"blog-api/config"
+	"blog-api/mail"
+	"blog-api/middleware"
+	"blog-api/models"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
+)
+
+var (
+	magicLinkLimiter     *middleware.RateLimiter
+	magicLinkLimiterOnce sync.Once
+)
+
+// RequestMagicLink handles emailing a one-time sign-in link
+func RequestMagicLink(c *gin.Context) {
+	var req models.MagicLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !allowMagicLinkRequest(req.Email) {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many sign-in links requested for this email, please try again later"})
+		return
+	}
+
+	// The response is the same whether or not the account exists so that
+	// this endpoint cannot be used to discover registered email addresses
+	response := gin.H{
+		"message": "If an account with that email exists, a sign-in link has been sent",
+	}
+
+	var user models.User
+	if err := config.DB.Where("email = ?", req.Email).First(&user).Error; err != nil {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	if err := sendMagicLinkEmail(user); err != nil {
+		log.Printf("Failed to send sign-in link to user %s: %v", user.ID, err)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ConsumeMagicLink handles signing in with the token from a sign-in link. The
+// response is the same as for a password login.
+func ConsumeMagicLink(c *gin.Context) {
+	var req models.MagicLinkConsumeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userToken, err := consumeUserToken(config.DB, req.Token, models.TokenPurposeMagicLink)
+	if err != nil {
+		if errors.Is(err, errUserTokenInvalid) {
+			audit.Record(c, audit.Event{
+				Action:  models.AuditActionLoginMagicLink,
+				Outcome: models.AuditOutcomeFailure,
+				Reason:  "Invalid or expired sign-in link",
+			})
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired sign-in link"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign in"})
+		return
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, "id = ?", userToken.UserID).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired sign-in link"})
+		return
+	}
+
+	// Following the link proves the user owns the address
+	if user.EmailVerifiedAt == nil {
+		now := time.Now()
+		if err := config.DB.Model(&user).Update("email_verified_at", now).Error; err != nil {
+			log.Printf("Failed to mark email verified for user %s: %v", user.ID, err)
+		} else {
+			user.EmailVerifiedAt = &now
+		}
+	}
+
+	if err := clearLoginThrottle(user.Email); err != nil {
+		log.Printf("Failed to clear login throttle for user %s: %v", user.ID, err)
+	}
+
+	completeLogin(c, user, models.AuditActionLoginMagicLink)
+}
+
+// allowMagicLinkRequest applies the per-address limit on sign-in link requests.
+// It applies whether or not an account exists, so hitting the limit reveals
+// nothing about registered addresses.
+func allowMagicLinkRequest(email string) bool {
+	magicLinkLimiterOnce.Do(func() {
+		perHour := config.MagicLinkMaxPerHour()
+		if perHour < 1 {
+			perHour = 1
+		}
+		magicLinkLimiter = middleware.NewRateLimiter(rate.Every(time.Hour/time.Duration(perHour)), perHour)
+
+		// Start cleanup goroutine
+		go func() {
+			ticker := time.NewTicker(time.Hour)
+			defer ticker.Stop()
+			for range ticker.C {
+				magicLinkLimiter.Cleanup()
+			}
+		}()
+	})
+
+	return magicLinkLimiter.GetLimiter(strings.ToLower(strings.TrimSpace(email))).Allow()
+}
+
+// sendMagicLinkEmail issues a sign-in token for the user and emails the link
+func sendMagicLinkEmail(user models.User) error {
+	rawToken, err := createUserToken(config.DB, user.ID, models.TokenPurposeMagicLink, config.MagicLinkTTL())
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/magic-link?token=%s", config.AppURL(), url.QueryEscape(rawToken))
+	return mail.Send(mail.Message{
+		To:      user.Email,
+		Subject: "Your sign-in link",
+		Body: fmt.Sprintf("Hi %s,\n\nUse the link below to sign in. It expires in %s and can only be used once.\n\n%s\n\nIf you did not request this link, you can ignore this email.\n",
+			user.Username, config.MagicLinkTTL(), link),
+	})
+}
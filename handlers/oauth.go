@@ -0,0 +1,554 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"blog-api/audit"
+	"blog-api/config"
+	"blog-api/models"
+	"blog-api/oauth"
+	"blog-api/textutil"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	errOAuthStateInvalid    = errors.New("invalid or expired OAuth state")
+	errOAuthEmailUnverified = errors.New("provider did not return a verified email")
+	errOAuthAccountExists   = errors.New("an unverified account uses this email")
+	errOAuthIdentityTaken   = errors.New("identity is linked to another account")
+)
+
+// oauthLinkCookie holds the token that binds a link flow to the browser that started it
+const oauthLinkCookie = "oauth_link"
+
+// ListOAuthProviders handles listing the providers users can sign in with
+func ListOAuthProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"providers": oauth.Names(),
+	})
+}
+
+// StartOAuthLogin handles starting a sign-in with an external provider. The
+// client sends the browser to the returned authorization URL. An optional
+// invite_code query parameter is used if the sign-in creates an account.
+func StartOAuthLogin(c *gin.Context) {
+	startOAuthFlow(c, nil, c.Query("invite_code"))
+}
+
+// OAuthCallback handles the provider redirecting back with an authorization
+// code. The code and state are read from the query string on GET (the
+// provider redirect) or from the JSON body on POST. Depending on how the flow
+// was started, the identity is used to sign in or is linked to the signed-in user.
+func OAuthCallback(c *gin.Context) {
+	provider, ok := oauth.Get(c.Param("provider"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown OAuth provider"})
+		return
+	}
+
+	// The user declined or the provider failed
+	if providerError := c.Query("error"); providerError != "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":       "Sign-in was not completed",
+			"reason":      providerError,
+			"description": c.Query("error_description"),
+		})
+		return
+	}
+
+	var req models.OAuthCallbackRequest
+	var err error
+	if c.Request.Method == http.MethodPost {
+		err = c.ShouldBindJSON(&req)
+	} else {
+		err = c.ShouldBindQuery(&req)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	state, err := consumeOAuthState(provider.Name(), req.State)
+	if err != nil {
+		if errors.Is(err, errOAuthStateInvalid) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired OAuth state, please start again"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to complete sign-in"})
+		return
+	}
+
+	// A link flow must finish in the browser that started it, otherwise a
+	// victim could be tricked into linking their identity to someone else
+	if state.UserID != nil {
+		bound := oauthLinkBound(c, state)
+		clearOAuthLinkCookie(c)
+		if !bound {
+			audit.Record(c, audit.Event{
+				Action:  models.AuditActionIdentityLink,
+				ActorID: *state.UserID,
+				Outcome: models.AuditOutcomeFailure,
+				Reason:  "Link was completed in another browser",
+			})
+			c.JSON(http.StatusForbidden, gin.H{"error": "This link was started in another browser, please start again"})
+			return
+		}
+	}
+
+	identity, err := provider.Exchange(c.Request.Context(), req.Code, state.CodeVerifier, state.Nonce, state.RedirectURI)
+	if err != nil {
+		log.Printf("OAuth exchange with %s failed: %v", provider.Name(), err)
+		audit.Record(c, audit.Event{
+			Action:  models.AuditActionLoginOAuth,
+			Outcome: models.AuditOutcomeFailure,
+			Reason:  "Exchange with " + provider.Name() + " failed",
+		})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Failed to sign in with " + provider.Name()})
+		return
+	}
+
+	// Link the identity to the user who started the flow
+	if state.UserID != nil {
+		oauthIdentity, err := linkOAuthIdentity(*state.UserID, identity)
+		if err != nil {
+			if errors.Is(err, errOAuthIdentityTaken) {
+				c.JSON(http.StatusConflict, gin.H{"error": "This " + provider.Name() + " account is linked to another user"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to link identity"})
+			return
+		}
+
+		audit.Record(c, audit.Event{
+			Action:     models.AuditActionIdentityLink,
+			ActorID:    *state.UserID,
+			TargetType: models.AuditTargetIdentity,
+			TargetID:   oauthIdentity.ID,
+			Reason:     provider.Name(),
+		})
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":  "Identity linked successfully",
+			"identity": convertOAuthIdentityToResponse(*oauthIdentity),
+		})
+		return
+	}
+
+	user, err := findOrCreateOAuthUser(identity, state.InviteCode)
+	if err != nil {
+		if respondRegistrationError(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, errOAuthEmailUnverified):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Your " + provider.Name() + " account has no verified email address"})
+		case errors.Is(err, errOAuthAccountExists):
+			c.JSON(http.StatusConflict, gin.H{"error": "An account with this email already exists, sign in with your password and link " + provider.Name() + " from your profile"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign in"})
+		}
+		return
+	}
+
+	completeLogin(c, *user, models.AuditActionLoginOAuth)
+}
+
+// LinkOAuthIdentity handles starting the flow that links another provider
+// account to the authenticated user
+func LinkOAuthIdentity(c *gin.Context) {
+	// Get user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	userModel := user.(models.User)
+	startOAuthFlow(c, &userModel.ID, "")
+}
+
+// ListOAuthIdentities handles listing the provider accounts linked to the authenticated user
+func ListOAuthIdentities(c *gin.Context) {
+	// Get user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	userModel := user.(models.User)
+
+	var identities []models.OAuthIdentity
+	if err := config.DB.Where("user_id = ?", userModel.ID).Order("created_at ASC").Find(&identities).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch identities"})
+		return
+	}
+
+	// Convert to response format
+	identitiesResponse := make([]models.OAuthIdentityResponse, 0, len(identities))
+	for _, identity := range identities {
+		identitiesResponse = append(identitiesResponse, convertOAuthIdentityToResponse(identity))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"identities":   identitiesResponse,
+		"has_password": userModel.PasswordHash != "",
+	})
+}
+
+// UnlinkOAuthIdentity handles removing a linked provider account. The last
+// sign-in method of an account without a password cannot be removed.
+func UnlinkOAuthIdentity(c *gin.Context) {
+	// Get user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	userModel := user.(models.User)
+
+	var identity models.OAuthIdentity
+	if err := config.DB.Where("id = ? AND user_id = ?", c.Param("id"), userModel.ID).First(&identity).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Identity not found"})
+		return
+	}
+
+	// Without a password, another identity or a passkey must be left to sign in with
+	if userModel.PasswordHash == "" {
+		var identities, passkeys int64
+		config.DB.Model(&models.OAuthIdentity{}).Where("user_id = ?", userModel.ID).Count(&identities)
+		config.DB.Model(&models.WebAuthnCredential{}).Where("user_id = ?", userModel.ID).Count(&passkeys)
+		if identities <= 1 && passkeys == 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "This is your only way to sign in, set a password through password reset before unlinking it"})
+			return
+		}
+	}
+
+	if err := config.DB.Delete(&identity).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlink identity"})
+		return
+	}
+
+	audit.Record(c, audit.Event{
+		Action:     models.AuditActionIdentityUnlink,
+		TargetType: models.AuditTargetIdentity,
+		TargetID:   identity.ID,
+		Reason:     identity.Provider,
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Identity unlinked successfully",
+	})
+}
+
+// startOAuthFlow stores a new authorization request and responds with the
+// provider URL. A non-nil userID links the resulting identity to that user;
+// the request is then bound to this browser with an HttpOnly cookie.
+func startOAuthFlow(c *gin.Context, userID *string, inviteCode string) {
+	provider, ok := oauth.Get(c.Param("provider"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown OAuth provider"})
+		return
+	}
+
+	rawState, err := generateOpaqueToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start sign-in"})
+		return
+	}
+	codeVerifier, err := generateOpaqueToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start sign-in"})
+		return
+	}
+	nonce, err := generateOpaqueToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start sign-in"})
+		return
+	}
+
+	var linkBinding string
+	if userID != nil {
+		if linkBinding, err = generateOpaqueToken(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start sign-in"})
+			return
+		}
+	}
+
+	redirectURI := config.OAuthRedirectURL(provider.Name())
+	authorizationURL, err := provider.AuthCodeURL(c.Request.Context(), rawState, nonce, oauth.CodeChallenge(codeVerifier), redirectURI)
+	if err != nil {
+		log.Printf("Failed to build %s authorization URL: %v", provider.Name(), err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "OAuth provider is unavailable"})
+		return
+	}
+
+	// Forget abandoned authorization requests
+	config.DB.Where("expires_at < ?", time.Now()).Delete(&models.OAuthState{})
+
+	state := models.OAuthState{
+		ID:           uuid.New().String(),
+		Provider:     provider.Name(),
+		StateHash:    hashToken(rawState),
+		CodeVerifier: codeVerifier,
+		Nonce:        nonce,
+		RedirectURI:  redirectURI,
+		UserID:       userID,
+		InviteCode:   textutil.Truncate(inviteCode, 32),
+		ExpiresAt:    time.Now().Add(config.OAuthStateTTL()),
+	}
+	if linkBinding != "" {
+		state.LinkBindingHash = hashToken(linkBinding)
+	}
+	if err := config.DB.Create(&state).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start sign-in"})
+		return
+	}
+
+	if linkBinding != "" {
+		setOAuthLinkCookie(c, linkBinding, int(config.OAuthStateTTL().Seconds()))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"authorization_url": authorizationURL,
+		"state":             rawState,
+		"expires_in":        int64(config.OAuthStateTTL().Seconds()),
+	})
+}
+
+// oauthLinkBound reports whether the request carries the cookie set when the
+// link flow was started
+func oauthLinkBound(c *gin.Context, state *models.OAuthState) bool {
+	if state.LinkBindingHash == "" {
+		return false
+	}
+	rawBinding, err := c.Cookie(oauthLinkCookie)
+	if err != nil || rawBinding == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(hashToken(rawBinding)), []byte(state.LinkBindingHash)) == 1
+}
+
+// setOAuthLinkCookie stores the link binding token in an HttpOnly cookie scoped
+// to the OAuth endpoints. A negative maxAge removes it.
+func setOAuthLinkCookie(c *gin.Context, value string, maxAge int) {
+	secure := c.Request.TLS != nil || strings.HasPrefix(config.AppURL(), "https://")
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oauthLinkCookie, value, maxAge, "/api/v1/auth/oauth", "", secure, true)
+}
+
+// clearOAuthLinkCookie removes the link binding cookie
+func clearOAuthLinkCookie(c *gin.Context) {
+	setOAuthLinkCookie(c, "", -1)
+}
+
+// consumeOAuthState marks a pending authorization request as used and returns it
+func consumeOAuthState(providerName, rawState string) (*models.OAuthState, error) {
+	var state models.OAuthState
+	if err := config.DB.Where("state_hash = ? AND provider = ?", hashToken(rawState), providerName).First(&state).Error; err != nil {
+		return nil, errOAuthStateInvalid
+	}
+
+	if state.UsedAt != nil || time.Now().After(state.ExpiresAt) {
+		return nil, errOAuthStateInvalid
+	}
+
+	// Guard against the same state being used twice concurrently
+	result := config.DB.Model(&models.OAuthState{}).
+		Where("id = ? AND used_at IS NULL", state.ID).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errOAuthStateInvalid
+	}
+
+	return &state, nil
+}
+
+// findOrCreateOAuthUser returns the user an identity signs in as. A known
+// identity signs in as its linked user. Otherwise the identity is linked to
+// the account with the same verified email, or a new account is created if
+// the registration mode allows it.
+func findOrCreateOAuthUser(identity *oauth.Identity, inviteCode string) (*models.User, error) {
+	var user models.User
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		var existing models.OAuthIdentity
+		err := tx.Where("provider = ? AND subject = ?", identity.Provider, identity.Subject).First(&existing).Error
+		if err == nil {
+			if err := tx.Model(&existing).Updates(map[string]interface{}{
+				"email":         identity.Email,
+				"username":      identity.Username,
+				"last_login_at": now,
+			}).Error; err != nil {
+				return err
+			}
+			return tx.First(&user, "id = ?", existing.UserID).Error
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		// Accounts are only matched and created by an address the provider vouches for
+		if identity.Email == "" || !identity.EmailVerified {
+			return errOAuthEmailUnverified
+		}
+
+		err = tx.Where("email = ?", identity.Email).First(&user).Error
+		switch {
+		case err == nil:
+			// Whoever registered an unverified account with this address may
+			// not own it, so it must not be taken over by linking
+			if user.EmailVerifiedAt == nil {
+				return errOAuthAccountExists
+			}
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			invite, err := checkRegistration(identity.Email, inviteCode)
+			if err != nil {
+				return err
+			}
+			if invite != nil {
+				if err := redeemInvite(tx, invite.ID); err != nil {
+					return err
+				}
+			}
+
+			username, err := uniqueUsername(tx, identity)
+			if err != nil {
+				return err
+			}
+
+			user = models.User{
+				ID:              uuid.New().String(),
+				Username:        username,
+				Email:           identity.Email,
+				EmailVerifiedAt: &now,
+				Role:            registrationRole(invite),
+				DisplayName:     textutil.Truncate(identity.Name, 100),
+			}
+			// Avatars are shown on public profiles, so only plain web links are kept
+			if utf8.RuneCountInString(identity.AvatarURL) <= 500 && isHTTPURL(identity.AvatarURL) {
+				user.AvatarURL = identity.AvatarURL
+			}
+			if err := tx.Create(&user).Error; err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+
+		return tx.Create(&models.OAuthIdentity{
+			ID:          uuid.New().String(),
+			UserID:      user.ID,
+			Provider:    identity.Provider,
+			Subject:     identity.Subject,
+			Email:       identity.Email,
+			Username:    identity.Username,
+			LastLoginAt: &now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// linkOAuthIdentity links an identity to a user, or returns the existing link
+func linkOAuthIdentity(userID string, identity *oauth.Identity) (*models.OAuthIdentity, error) {
+	var existing models.OAuthIdentity
+	err := config.DB.Where("provider = ? AND subject = ?", identity.Provider, identity.Subject).First(&existing).Error
+	if err == nil {
+		if existing.UserID != userID {
+			return nil, errOAuthIdentityTaken
+		}
+		return &existing, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	oauthIdentity := models.OAuthIdentity{
+		ID:       uuid.New().String(),
+		UserID:   userID,
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+		Username: identity.Username,
+	}
+	if err := config.DB.Create(&oauthIdentity).Error; err != nil {
+		return nil, err
+	}
+
+	return &oauthIdentity, nil
+}
+
+// uniqueUsername derives an unused username from the identity's username or email
+func uniqueUsername(db *gorm.DB, identity *oauth.Identity) (string, error) {
+	base := identity.Username
+	if base == "" {
+		base = strings.SplitN(identity.Email, "@", 2)[0]
+	}
+
+	// Keep characters that are safe in profile URLs
+	var b strings.Builder
+	for _, r := range strings.ToLower(base) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_' || r == '-' || r == '.' {
+			b.WriteRune(r)
+		}
+	}
+	base = textutil.Truncate(b.String(), 40)
+	if len(base) < 3 {
+		base = "user"
+	}
+
+	for i := 1; i <= 100; i++ {
+		candidate := base
+		if i > 1 {
+			candidate = fmt.Sprintf("%s%d", base, i)
+		}
+
+		var count int64
+		if err := db.Model(&models.User{}).Unscoped().Where("username = ?", candidate).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return candidate, nil
+		}
+	}
+
+	return base + "-" + uuid.New().String()[:8], nil
+}
+
+// isHTTPURL reports whether s is an absolute http or https URL
+func isHTTPURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// convertOAuthIdentityToResponse converts a linked identity to response format
+func convertOAuthIdentityToResponse(identity models.OAuthIdentity) models.OAuthIdentityResponse {
+	return models.OAuthIdentityResponse{
+		ID:          identity.ID,
+		Provider:    identity.Provider,
+		Email:       identity.Email,
+		Username:    identity.Username,
+		LastLoginAt: identity.LastLoginAt,
+		CreatedAt:   identity.CreatedAt,
+	}
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"blog-api/config"
+	"blog-api/models"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// ChangePassword handles the authenticated user changing their password. Every
+// token issued before the change stops working, including the one used for
+// this request, so the response carries a new token pair.
+func ChangePassword(c *gin.Context) {
+	// Get user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	userModel := user.(models.User)
+
+	var req models.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Accounts created through social login have no password to confirm yet
+	if userModel.PasswordHash != "" {
+		if err := bcrypt.CompareHashAndPassword([]byte(userModel.PasswordHash), []byte(req.CurrentPassword)); err != nil {
+			recordAccountFailure(c, models.AuditActionPasswordChange, userModel.ID, "Current password is incorrect")
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Current password is incorrect"})
+			return
+		}
+	}
+
+	// Check the new password against the password policy
+	if !validatePassword(c, req.NewPassword, userModel) {
+		return
+	}
+
+	// Hash password
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		return setPassword(tx, userModel.ID, string(hashedPassword))
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change password"})
+		return
+	}
+
+	recordAccountEvent(c, models.AuditActionPasswordChange, userModel.ID)
+
+	// Start a new session in place of the revoked ones
+	tokens, err := issueTokens(c, userModel.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Password changed successfully",
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+	})
+}
+
+// setPassword stores a new password hash, records when it changed so older
+// access tokens are rejected, and ends every existing session
+func setPassword(tx *gorm.DB, userID, passwordHash string) error {
+	if err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"password_hash":           passwordHash,
+		"password_reset_required": false,
+		"password_changed_at":     time.Now(),
+	}).Error; err != nil {
+		return err
+	}
+
+	if err := recordPasswordHistory(tx, userID, passwordHash); err != nil {
+		return err
+	}
+
+	// Changing the password ends every existing session
+	return revokeUserSessions(tx, userID, "")
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"blog-api/config"
+	"blog-api/models"
+	"blog-api/password"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// validatePassword checks a new password for the user against the password
+// policy, the breached password list, and, for existing users, their recent
+// passwords. It responds with the failed rule and returns false when the
+// password is not acceptable.
+func validatePassword(c *gin.Context, newPassword string, user models.User) bool {
+	err := password.Check(newPassword, user.Username, user.Email)
+	if err == nil && user.ID != "" {
+		err = checkPasswordReuse(newPassword, user)
+	}
+	if err == nil {
+		return true
+	}
+
+	var violation *password.Violation
+	if errors.As(err, &violation) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": violation.Message, "rule": violation.Rule})
+		return false
+	}
+
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate password"})
+	return false
+}
+
+// checkPasswordReuse rejects the user's current password and the previous
+// ones kept in their password history
+func checkPasswordReuse(newPassword string, user models.User) error {
+	historySize := password.DefaultPolicy.HistorySize
+	if historySize <= 0 {
+		return nil
+	}
+
+	hashes := []string{}
+	if user.PasswordHash != "" {
+		hashes = append(hashes, user.PasswordHash)
+	}
+
+	var history []models.PasswordHistory
+	if err := config.DB.Where("user_id = ?", user.ID).
+		Order("created_at DESC").Limit(historySize).Find(&history).Error; err != nil {
+		return err
+	}
+	for _, entry := range history {
+		hashes = append(hashes, entry.PasswordHash)
+	}
+
+	for _, hash := range hashes {
+		if bcrypt.CompareHashAndPassword([]byte(hash), []byte(newPassword)) == nil {
+			return &password.Violation{Rule: password.RuleReused, Message: "Password was used recently, please choose another"}
+		}
+	}
+
+	return nil
+}
+
+// recordPasswordHistory remembers a newly set password hash and forgets the
+// ones that fall outside the configured history size
+func recordPasswordHistory(tx *gorm.DB, userID, passwordHash string) error {
+	historySize := password.DefaultPolicy.HistorySize
+	if historySize <= 0 {
+		return nil
+	}
+
+	if err := tx.Create(&models.PasswordHistory{
+		ID:           uuid.New().String(),
+		UserID:       userID,
+		PasswordHash: passwordHash,
+	}).Error; err != nil {
+		return err
+	}
+
+	var kept []string
+	if err := tx.Model(&models.PasswordHistory{}).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").Limit(historySize).
+		Pluck("id", &kept).Error; err != nil {
+		return err
+	}
+
+	return tx.Where("user_id = ? AND id NOT IN ?", userID, kept).Delete(&models.PasswordHistory{}).Error
+}
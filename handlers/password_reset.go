@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"blog-api/config"
+	"blog-api/mail"
+	"blog-api/models"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// ForgotPassword handles requesting a password reset email
+func ForgotPassword(c *gin.Context) {
+	var req models.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// The response is the same whether or not the account exists so that
+	// this endpoint cannot be used to discover registered email addresses
+	response := gin.H{
+		"message": "If an account with that email exists, a password reset link has been sent",
+	}
+
+	var user models.User
+	if err := config.DB.Where("email = ?", req.Email).First(&user).Error; err != nil {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	if err := sendPasswordResetEmail(user); err != nil {
+		log.Printf("Failed to send password reset email to user %s: %v", user.ID, err)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ResetPassword handles setting a new password with a reset token
+func ResetPassword(c *gin.Context) {
+	var req models.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Find the account being reset so the new password can be checked against it
+	var resetToken models.UserToken
+	if err := config.DB.Preload("User").
+		Where("token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?",
+			hashToken(req.Token), models.TokenPurposePasswordReset, time.Now()).
+		First(&resetToken).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset token"})
+		return
+	}
+
+	if !validatePassword(c, req.Password, resetToken.User) {
+		return
+	}
+
+	// Hash new password
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	var userID string
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		userToken, err := consumeUserToken(tx, req.Token, models.TokenPurposePasswordReset)
+		if err != nil {
+			return err
+		}
+		userID = userToken.UserID
+
+		return setPassword(tx, userToken.UserID, string(hashedPassword))
+	})
+	if err != nil {
+		if errors.Is(err, errUserTokenInvalid) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset token"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+
+	recordAccountEvent(c, models.AuditActionPasswordReset, userID)
+
+	// A successful reset also lifts any login lockout
+	var user models.User
+	if err := config.DB.First(&user, "id = ?", userID).Error; err == nil {
+		if err := clearLoginThrottle(user.Email); err != nil {
+			log.Printf("Failed to clear login throttle for user %s: %v", user.ID, err)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Password reset successfully",
+	})
+}
+
+// sendPasswordResetEmail issues a reset token for the user and emails the link
+func sendPasswordResetEmail(user models.User) error {
+	rawToken, err := createUserToken(config.DB, user.ID, models.TokenPurposePasswordReset, config.PasswordResetTokenTTL())
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", config.AppURL(), url.QueryEscape(rawToken))
+	return mail.Send(mail.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nUse the link below to choose a new password. It expires in %s.\n\n%s\n\nIf you did not request a password reset, you can ignore this email.\n",
+			user.Username, config.PasswordResetTokenTTL(), link),
+	})
+}
@@ -0,0 +1,306 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"blog-api/config"
+	"blog-api/diff"
+	"blog-api/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ListPostRevisions handles listing a post's revisions, newest first
+func ListPostRevisions(c *gin.Context) {
+	_, post, ok := findRevisablePost(c)
+	if !ok {
+		return
+	}
+
+	// Get pagination parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset := (page - 1) * limit
+
+	var revisions []models.PostRevision
+	var total int64
+
+	config.DB.Model(&models.PostRevision{}).Where("post_id = ?", post.ID).Count(&total)
+
+	if err := config.DB.Where("post_id = ?", post.ID).
+		Omit("content").
+		Offset(offset).
+		Limit(limit).
+		Order("number DESC").
+		Find(&revisions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch revisions"})
+		return
+	}
+
+	editors := loadRevisionEditors(revisions...)
+
+	// Convert to response format
+	var revisionsResponse []models.PostRevisionSummary
+	for _, revision := range revisions {
+		revisionsResponse = append(revisionsResponse, models.PostRevisionSummary{
+			Number:       revision.Number,
+			Title:        revision.Title,
+			Editor:       editors[revision.EditorID],
+			RestoredFrom: revision.RestoredFrom,
+			CreatedAt:    revision.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"revisions": revisionsResponse,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+// GetPostRevision handles getting a single revision of a post with its full content
+func GetPostRevision(c *gin.Context) {
+	_, post, ok := findRevisablePost(c)
+	if !ok {
+		return
+	}
+
+	revision, ok := findPostRevision(c, post.ID, c.Param("number"))
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"revision": convertPostRevisionToResponse(revision, loadRevisionEditors(revision)),
+	})
+}
+
+// DiffPostRevisions handles comparing two revisions of a post line by line
+func DiffPostRevisions(c *gin.Context) {
+	_, post, ok := findRevisablePost(c)
+	if !ok {
+		return
+	}
+
+	if c.Query("from") == "" || c.Query("to") == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from and to revision numbers are required"})
+		return
+	}
+
+	from, ok := findPostRevision(c, post.ID, c.Query("from"))
+	if !ok {
+		return
+	}
+	to, ok := findPostRevision(c, post.ID, c.Query("to"))
+	if !ok {
+		return
+	}
+
+	lines, err := diff.Lines(from.Content, to.Content)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Revisions differ too much to compare"})
+		return
+	}
+	added, removed := diff.Stats(lines)
+
+	c.JSON(http.StatusOK, gin.H{
+		"diff": models.PostRevisionDiffResponse{
+			From:         from.Number,
+			To:           to.Number,
+			TitleChanged: from.Title != to.Title,
+			OldTitle:     from.Title,
+			NewTitle:     to.Title,
+			OldTags:      from.Tags,
+			NewTags:      to.Tags,
+			Lines:        lines,
+			LinesAdded:   added,
+			LinesRemoved: removed,
+		},
+	})
+}
+
+// RestorePostRevision handles restoring an older revision. The post gets the
+// revision's title, content, and tags, and the restore is stored as a new revision.
+func RestorePostRevision(c *gin.Context) {
+	userModel, post, ok := findRevisablePost(c)
+	if !ok {
+		return
+	}
+
+	revision, ok := findPostRevision(c, post.ID, c.Param("number"))
+	if !ok {
+		return
+	}
+
+	previous := post
+	titleChanged := revision.Title != post.Title
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&post).Updates(map[string]interface{}{
+			"title":   revision.Title,
+			"content": revision.Content,
+			"tags":    revision.Tags,
+		}).Error; err != nil {
+			return err
+		}
+		post.Title, post.Content, post.Tags = revision.Title, revision.Content, revision.Tags
+
+		// The restored title gets its slug back, or a new one if that is taken
+		if titleChanged {
+			if err := setPostSlug(tx, &post, ""); err != nil {
+				return err
+			}
+		}
+		return recordPostRevision(tx, previous, post, userModel.ID, &revision.Number)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore revision"})
+		return
+	}
+
+	// Reload post with author
+	config.DB.Preload("Author").Preload("Likes").First(&post, "id = ?", post.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Revision restored successfully",
+		"post":    convertPostToResponse(post),
+	})
+}
+
+// recordPostRevision stores the updated post as a new revision. Nothing is
+// stored when the title, content, and tags did not change. Posts written
+// before revisions were introduced first get their previous state stored as
+// revision 1. Callers must hold the post's row lock, which the UPDATE of the
+// post takes, so that revision numbers are handed out one at a time.
+func recordPostRevision(tx *gorm.DB, previous, updated models.Post, editorID string, restoredFrom *int) error {
+	if previous.Title == updated.Title && previous.Content == updated.Content && slices.Equal(previous.Tags, updated.Tags) {
+		return nil
+	}
+
+	var last int
+	if err := tx.Model(&models.PostRevision{}).
+		Where("post_id = ?", updated.ID).
+		Select("COALESCE(MAX(number), 0)").
+		Scan(&last).Error; err != nil {
+		return err
+	}
+
+	if last == 0 {
+		if err := createPostRevision(tx, previous, 1, previous.AuthorID, nil); err != nil {
+			return err
+		}
+		last = 1
+	}
+
+	return createPostRevision(tx, updated, last+1, editorID, restoredFrom)
+}
+
+// createPostRevision stores the post's current title, content, and tags as revision number
+func createPostRevision(tx *gorm.DB, post models.Post, number int, editorID string, restoredFrom *int) error {
+	return tx.Create(&models.PostRevision{
+		ID:           uuid.New().String(),
+		PostID:       post.ID,
+		Number:       number,
+		EditorID:     editorID,
+		Title:        post.Title,
+		Content:      post.Content,
+		Tags:         post.Tags,
+		RestoredFrom: restoredFrom,
+	}).Error
+}
+
+// findRevisablePost loads the signed-in user and the post in the URL, which
+// must be theirs unless they may edit any post, responding with an error
+// otherwise. It reports whether both were found.
+func findRevisablePost(c *gin.Context) (models.User, models.Post, bool) {
+	var post models.Post
+
+	// Get user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return models.User{}, post, false
+	}
+
+	userModel := user.(models.User)
+
+	if err := config.DB.First(&post, "id = ?", c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		return userModel, post, false
+	}
+
+	// Check if user is the author or may edit any post
+	if post.AuthorID != userModel.ID && !userModel.HasPermission(models.PermissionEditAnyPost) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only access the revisions of your own posts"})
+		return userModel, post, false
+	}
+
+	return userModel, post, true
+}
+
+// findPostRevision loads the post's revision with the given number, responding
+// with an error otherwise. It reports whether the revision was found.
+func findPostRevision(c *gin.Context, postID, number string) (models.PostRevision, bool) {
+	var revision models.PostRevision
+
+	n, err := strconv.Atoi(number)
+	if err != nil || n < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision number"})
+		return revision, false
+	}
+
+	err = config.DB.Where("post_id = ? AND number = ?", postID, n).First(&revision).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Revision not found"})
+		return revision, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch revision"})
+		return revision, false
+	}
+
+	return revision, true
+}
+
+// loadRevisionEditors returns the editors of the revisions by ID. Editors whose
+// accounts were deleted are missing from the map.
+func loadRevisionEditors(revisions ...models.PostRevision) map[string]*models.UserResponse {
+	var ids []string
+	for _, revision := range revisions {
+		ids = append(ids, revision.EditorID)
+	}
+
+	editors := make(map[string]*models.UserResponse)
+	if len(ids) == 0 {
+		return editors
+	}
+
+	var users []models.User
+	config.DB.Where("id IN ?", ids).Find(&users)
+	for _, user := range users {
+		response := convertUserToResponse(user)
+		editors[user.ID] = &response
+	}
+	return editors
+}
+
+// convertPostRevisionToResponse converts a revision to response format
+func convertPostRevisionToResponse(revision models.PostRevision, editors map[string]*models.UserResponse) models.PostRevisionResponse {
+	return models.PostRevisionResponse{
+		Number:       revision.Number,
+		Title:        revision.Title,
+		Content:      revision.Content,
+		Tags:         revision.Tags,
+		Editor:       editors[revision.EditorID],
+		RestoredFrom: revision.RestoredFrom,
+		CreatedAt:    revision.CreatedAt,
+	}
+}
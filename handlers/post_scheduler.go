@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"log"
+	"time"
+
+	"blog-api/config"
+	"blog-api/models"
+)
+
+// StartPostScheduler runs a background job that publishes scheduled posts once
+// their publish time has passed
+func StartPostScheduler() {
+	go func() {
+		ticker := time.NewTicker(config.PostSchedulerInterval())
+		defer ticker.Stop()
+		for {
+			if published, err := publishDuePosts(); err != nil {
+				log.Printf("Failed to publish scheduled posts: %v", err)
+			} else if published > 0 {
+				log.Printf("Published %d scheduled posts", published)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// publishDuePosts publishes every scheduled post whose publish time has passed.
+// Several instances may run it at once: the single conditional update only
+// matches posts that are still scheduled, so each post is published exactly once.
+func publishDuePosts() (int64, error) {
+	now := time.Now()
+	// Assignments run left to right, so published_at is filled in before publish_at is cleared
+	result := config.DB.Exec(
+		"UPDATE posts SET published_at = COALESCE(published_at, publish_at), publish_at = NULL, status = ?, updated_at = ? "+
+			"WHERE status = ? AND publish_at <= ? AND deleted_at IS NULL",
+		models.PostStatusPublished, now, models.PostStatusScheduled, now,
+	)
+	return result.RowsAffected, result.Error
+}
@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"blog-api/config"
 	"blog-api/models"
@@ -29,16 +31,47 @@ func CreatePost(c *gin.Context) {
 
 	userModel := user.(models.User)
 
+	// New posts start as drafts unless another status is asked for
+	status := req.Status
+	if status == "" {
+		status = models.PostStatusDraft
+	}
+	if !checkPublishRequest(c, userModel, status, req.PublishAt) {
+		return
+	}
+	if !checkCustomSlug(c, req.Slug) {
+		return
+	}
+
 	// Create post
 	post := models.Post{
-		ID:       uuid.New().String(),
-		Title:    req.Title,
-		Content:  req.Content,
-		Tags:     req.Tags,
-		AuthorID: userModel.ID,
+		ID:        uuid.New().String(),
+		Title:     req.Title,
+		Content:   req.Content,
+		Tags:      req.Tags,
+		AuthorID:  userModel.ID,
+		Status:    status,
+		PublishAt: req.PublishAt,
+	}
+	if status == models.PostStatusPublished {
+		now := time.Now()
+		post.PublishedAt = &now
 	}
 
-	if err := config.DB.Create(&post).Error; err != nil {
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&post).Error; err != nil {
+			return err
+		}
+		if err := setPostSlug(tx, &post, req.Slug); err != nil {
+			return err
+		}
+		return createPostRevision(tx, post, 1, userModel.ID, nil)
+	})
+	if errors.Is(err, errSlugTaken) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Slug is already in use"})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create post"})
 		return
 	}
@@ -47,22 +80,7 @@ func CreatePost(c *gin.Context) {
 	config.DB.Preload("Author").First(&post, post.ID)
 
 	// Convert to response format
-	postResponse := models.PostResponse{
-		ID:        post.ID,
-		Title:     post.Title,
-		Content:   post.Content,
-		Tags:      post.Tags,
-		AuthorID:  post.AuthorID,
-		Author: models.UserResponse{
-			ID:        post.Author.ID,
-			Username:  post.Author.Username,
-			Email:     post.Author.Email,
-			CreatedAt: post.Author.CreatedAt,
-		},
-		LikesCount: 0,
-		CreatedAt:  post.CreatedAt,
-		UpdatedAt:  post.UpdatedAt,
-	}
+	postResponse := convertPostToResponse(post)
 
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "Post created successfully",
@@ -81,15 +99,15 @@ func GetPosts(c *gin.Context) {
 	var posts []models.Post
 	var total int64
 
-	// Count total posts
-	config.DB.Model(&models.Post{}).Count(&total)
+	// Count total published posts
+	publishedPosts(config.DB.Model(&models.Post{})).Count(&total)
 
 	// Get posts with pagination
-	if err := config.DB.Preload("Author").
+	if err := publishedPosts(config.DB.Preload("Author")).
 		Preload("Likes").
 		Offset(offset).
 		Limit(limit).
-		Order("created_at DESC").
+		Order("published_at DESC").
 		Find(&posts).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch posts"})
 		return
@@ -98,23 +116,7 @@ func GetPosts(c *gin.Context) {
 	// Convert to response format
 	var postsResponse []models.PostResponse
 	for _, post := range posts {
-		postResponse := models.PostResponse{
-			ID:        post.ID,
-			Title:     post.Title,
-			Content:   post.Content,
-			Tags:      post.Tags,
-			AuthorID:  post.AuthorID,
-			Author: models.UserResponse{
-				ID:        post.Author.ID,
-				Username:  post.Author.Username,
-				Email:     post.Author.Email,
-				CreatedAt: post.Author.CreatedAt,
-			},
-			LikesCount: len(post.Likes),
-			CreatedAt:  post.CreatedAt,
-			UpdatedAt:  post.UpdatedAt,
-		}
-		postsResponse = append(postsResponse, postResponse)
+		postsResponse = append(postsResponse, convertPostToResponse(post))
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -129,17 +131,24 @@ func GetPosts(c *gin.Context) {
 
 // GetPost handles getting a single post by ID
 func GetPost(c *gin.Context) {
-	postID := c.Param("id")
+	respondWithPost(c, c.Param("id"))
+}
+
+// respondWithPost responds with the post, its comments, and its likes, or 404
+// when the post does not exist or the request may not see it
+func respondWithPost(c *gin.Context, postID string) {
+	// Leave out comments by muted and blocked users
+	hidden := hiddenAuthorIDs(c)
 
 	var post models.Post
 	if err := config.DB.Preload("Author").
 		Preload("Comments", func(db *gorm.DB) *gorm.DB {
-			return db.Preload("Author").Preload("Replies", func(db *gorm.DB) *gorm.DB {
-				return db.Preload("Author")
-			}).Where("parent_comment_id IS NULL")
+			return excludeAuthors(db.Preload("Author").Preload("Replies", func(db *gorm.DB) *gorm.DB {
+				return excludeAuthors(db.Preload("Author"), hidden)
+			}).Where("parent_comment_id IS NULL"), hidden)
 		}).
 		Preload("Likes").
-		First(&post, "id = ?", postID).Error; err != nil {
+		First(&post, "id = ?", postID).Error; err != nil || !canViewPost(c, post) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
 		return
 	}
@@ -158,35 +167,15 @@ func GetPost(c *gin.Context) {
 			ID:        like.ID,
 			PostID:    like.PostID,
 			UserID:    like.UserID,
-			User: models.UserResponse{
-				ID:        like.User.ID,
-				Username:  like.User.Username,
-				Email:     like.User.Email,
-				CreatedAt: like.User.CreatedAt,
-			},
+			User:      convertUserToResponse(like.User),
 			CreatedAt: like.CreatedAt,
 		}
 		likesResponse = append(likesResponse, likeResponse)
 	}
 
-	postResponse := models.PostResponse{
-		ID:        post.ID,
-		Title:     post.Title,
-		Content:   post.Content,
-		Tags:      post.Tags,
-		AuthorID:  post.AuthorID,
-		Author: models.UserResponse{
-			ID:        post.Author.ID,
-			Username:  post.Author.Username,
-			Email:     post.Author.Email,
-			CreatedAt: post.Author.CreatedAt,
-		},
-		Comments:   commentsResponse,
-		Likes:      likesResponse,
-		LikesCount: len(post.Likes),
-		CreatedAt:  post.CreatedAt,
-		UpdatedAt:  post.UpdatedAt,
-	}
+	postResponse := convertPostToResponse(post)
+	postResponse.Comments = commentsResponse
+	postResponse.Likes = likesResponse
 
 	c.JSON(http.StatusOK, gin.H{
 		"post": postResponse,
@@ -213,8 +202,8 @@ func UpdatePost(c *gin.Context) {
 		return
 	}
 
-	// Check if user is the author
-	if post.AuthorID != userModel.ID {
+	// Check if user is the author or may edit any post
+	if post.AuthorID != userModel.ID && !userModel.HasPermission(models.PermissionEditAnyPost) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "You can only update your own posts"})
 		return
 	}
@@ -237,8 +226,34 @@ func UpdatePost(c *gin.Context) {
 	if req.Tags != nil {
 		updates["tags"] = req.Tags
 	}
+	if !checkCustomSlug(c, req.Slug) {
+		return
+	}
+
+	// A new title gets a new slug unless one is given; old slugs keep redirecting
+	titleChanged := req.Title != "" && req.Title != post.Title
 
-	if err := config.DB.Model(&post).Updates(updates).Error; err != nil {
+	// Keep the previous version for the revision history
+	previous := post
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if len(updates) > 0 {
+			if err := tx.Model(&post).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+		if req.Slug != "" || titleChanged {
+			if err := setPostSlug(tx, &post, req.Slug); err != nil {
+				return err
+			}
+		}
+		return recordPostRevision(tx, previous, post, userModel.ID, nil)
+	})
+	if errors.Is(err, errSlugTaken) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Slug is already in use"})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update post"})
 		return
 	}
@@ -246,22 +261,7 @@ func UpdatePost(c *gin.Context) {
 	// Reload post with author
 	config.DB.Preload("Author").First(&post, post.ID)
 
-	postResponse := models.PostResponse{
-		ID:        post.ID,
-		Title:     post.Title,
-		Content:   post.Content,
-		Tags:      post.Tags,
-		AuthorID:  post.AuthorID,
-		Author: models.UserResponse{
-			ID:        post.Author.ID,
-			Username:  post.Author.Username,
-			Email:     post.Author.Email,
-			CreatedAt: post.Author.CreatedAt,
-		},
-		LikesCount: 0,
-		CreatedAt:  post.CreatedAt,
-		UpdatedAt:  post.UpdatedAt,
-	}
+	postResponse := convertPostToResponse(post)
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Post updated successfully",
@@ -269,6 +269,254 @@ func UpdatePost(c *gin.Context) {
 	})
 }
 
+// UpdatePostStatus handles moving a post through the draft, review, and publish workflow
+func UpdatePostStatus(c *gin.Context) {
+	postID := c.Param("id")
+
+	// Get user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	userModel := user.(models.User)
+
+	// Find post
+	var post models.Post
+	if err := config.DB.First(&post, "id = ?", postID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		return
+	}
+
+	// Check if user is the author or may edit any post
+	if post.AuthorID != userModel.ID && !userModel.HasPermission(models.PermissionEditAnyPost) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only change the status of your own posts"})
+		return
+	}
+
+	var req models.PostStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !post.CanTransitionTo(req.Status) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":  "Post cannot move from " + post.Status + " to " + req.Status,
+			"status": post.Status,
+		})
+		return
+	}
+
+	if !checkPublishRequest(c, userModel, req.Status, req.PublishAt) {
+		return
+	}
+
+	// publish_at is only kept while the post is scheduled
+	updates := map[string]interface{}{"status": req.Status, "publish_at": req.PublishAt}
+	if req.Status == models.PostStatusPublished && post.PublishedAt == nil {
+		updates["published_at"] = time.Now()
+	}
+
+	// Only apply the change if nobody moved the post in the meantime
+	result := config.DB.Model(&models.Post{}).
+		Where("id = ? AND status = ?", post.ID, post.Status).
+		Updates(updates)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update post status"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Post status was changed by someone else, please retry"})
+		return
+	}
+
+	// Reload post with author
+	config.DB.Preload("Author").Preload("Likes").First(&post, "id = ?", post.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Post status updated successfully",
+		"post":    convertPostToResponse(post),
+	})
+}
+
+// GetScheduledPosts handles listing upcoming scheduled posts, soonest first.
+// Editors see every scheduled post, other users only their own.
+func GetScheduledPosts(c *gin.Context) {
+	// Get user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	userModel := user.(models.User)
+
+	// Get pagination parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	offset := (page - 1) * limit
+
+	db := config.DB.Where("status = ?", models.PostStatusScheduled)
+	if !userModel.HasPermission(models.PermissionEditAnyPost) {
+		db = db.Where("author_id = ?", userModel.ID)
+	}
+
+	var posts []models.Post
+	var total int64
+
+	db.Session(&gorm.Session{}).Model(&models.Post{}).Count(&total)
+
+	if err := db.Session(&gorm.Session{}).Preload("Author").
+		Offset(offset).
+		Limit(limit).
+		Order("publish_at ASC").
+		Find(&posts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch posts"})
+		return
+	}
+
+	// Convert to response format
+	var postsResponse []models.PostResponse
+	for _, post := range posts {
+		postsResponse = append(postsResponse, convertPostToResponse(post))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"posts": postsResponse,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+// ReschedulePost handles moving a scheduled post to another publish time
+func ReschedulePost(c *gin.Context) {
+	postID := c.Param("id")
+
+	// Get user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	userModel := user.(models.User)
+
+	// Find post
+	var post models.Post
+	if err := config.DB.First(&post, "id = ?", postID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		return
+	}
+
+	// Check if user is the author or may edit any post
+	if post.AuthorID != userModel.ID && !userModel.HasPermission(models.PermissionEditAnyPost) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only reschedule your own posts"})
+		return
+	}
+
+	var req models.PostScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !checkPublishRequest(c, userModel, models.PostStatusScheduled, &req.PublishAt) {
+		return
+	}
+
+	// The scheduler may publish the post at any moment, so only move it while it is still scheduled
+	result := config.DB.Model(&models.Post{}).
+		Where("id = ? AND status = ?", post.ID, models.PostStatusScheduled).
+		Update("publish_at", req.PublishAt)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reschedule post"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Post is not scheduled", "status": post.Status})
+		return
+	}
+
+	// Reload post with author
+	config.DB.Preload("Author").Preload("Likes").First(&post, "id = ?", post.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Post rescheduled successfully",
+		"post":    convertPostToResponse(post),
+	})
+}
+
+// GetMyPosts handles listing the authenticated user's posts in any status
+func GetMyPosts(c *gin.Context) {
+	// Get user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	userModel := user.(models.User)
+
+	listPostsByStatus(c, config.DB.Where("author_id = ?", userModel.ID), "")
+}
+
+// GetReviewQueue handles listing posts for editors, waiting for review by default
+func GetReviewQueue(c *gin.Context) {
+	listPostsByStatus(c, config.DB, models.PostStatusInReview)
+}
+
+// listPostsByStatus responds with a page of the posts matched by db, narrowed
+// to the status in the query string or to defaultStatus when one is given
+func listPostsByStatus(c *gin.Context, db *gorm.DB, defaultStatus string) {
+	// Get pagination parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	offset := (page - 1) * limit
+
+	if status := c.DefaultQuery("status", defaultStatus); status != "" {
+		if !models.IsValidPostStatus(status) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
+			return
+		}
+		db = db.Where("status = ?", status)
+	}
+
+	var posts []models.Post
+	var total int64
+
+	db.Session(&gorm.Session{}).Model(&models.Post{}).Count(&total)
+
+	if err := db.Session(&gorm.Session{}).Preload("Author").
+		Preload("Likes").
+		Offset(offset).
+		Limit(limit).
+		Order("updated_at DESC").
+		Find(&posts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch posts"})
+		return
+	}
+
+	// Convert to response format
+	var postsResponse []models.PostResponse
+	for _, post := range posts {
+		postsResponse = append(postsResponse, convertPostToResponse(post))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"posts": postsResponse,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
 // DeletePost handles deleting a post
 func DeletePost(c *gin.Context) {
 	postID := c.Param("id")
@@ -289,8 +537,8 @@ func DeletePost(c *gin.Context) {
 		return
 	}
 
-	// Check if user is the author
-	if post.AuthorID != userModel.ID {
+	// Check if user is the author or may delete any post
+	if post.AuthorID != userModel.ID && !userModel.HasPermission(models.PermissionDeleteAnyPost) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "You can only delete your own posts"})
 		return
 	}
@@ -306,6 +554,74 @@ func DeletePost(c *gin.Context) {
 	})
 }
 
+// checkPublishRequest checks that the user may move a post to status and that
+// publishAt is given exactly when the post is being scheduled, responding with
+// an error otherwise. It reports whether the request may go ahead.
+func checkPublishRequest(c *gin.Context, user models.User, status string, publishAt *time.Time) bool {
+	// Publishing and scheduling need posts:publish; ownership is checked by the caller
+	if (status == models.PostStatusPublished || status == models.PostStatusScheduled) && !user.HasPermission(models.PermissionPublishPost) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to publish posts"})
+		return false
+	}
+
+	if status != models.PostStatusScheduled {
+		if publishAt != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "publish_at can only be set when scheduling a post"})
+			return false
+		}
+		return true
+	}
+
+	if publishAt == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "publish_at is required to schedule a post"})
+		return false
+	}
+	if !publishAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "publish_at must be in the future"})
+		return false
+	}
+	return true
+}
+
+// publishedPosts limits a post query to posts visible to everyone
+func publishedPosts(db *gorm.DB) *gorm.DB {
+	return db.Where("status = ?", models.PostStatusPublished)
+}
+
+// canViewPost reports whether the request may see the post. Posts that are
+// not published are only visible to their author and to editors.
+func canViewPost(c *gin.Context, post models.Post) bool {
+	if post.IsPublished() {
+		return true
+	}
+
+	user, exists := c.Get("user")
+	if !exists {
+		return false
+	}
+	userModel := user.(models.User)
+	return post.AuthorID == userModel.ID || userModel.HasPermission(models.PermissionEditAnyPost)
+}
+
+// convertPostToResponse converts a post to response format without its comments and likes
+func convertPostToResponse(post models.Post) models.PostResponse {
+	return models.PostResponse{
+		ID:          post.ID,
+		Slug:        post.Slug,
+		Title:       post.Title,
+		Content:     post.Content,
+		Tags:        post.Tags,
+		AuthorID:    post.AuthorID,
+		Author:      convertUserToResponse(post.Author),
+		Status:      post.Status,
+		PublishedAt: post.PublishedAt,
+		PublishAt:   post.PublishAt,
+		LikesCount:  len(post.Likes),
+		CreatedAt:   post.CreatedAt,
+		UpdatedAt:   post.UpdatedAt,
+	}
+}
+
 // convertCommentToResponse converts a comment to response format
 func convertCommentToResponse(comment models.Comment) models.CommentResponse {
 	// Convert replies
@@ -317,14 +633,9 @@ func convertCommentToResponse(comment models.Comment) models.CommentResponse {
 			AuthorID:        reply.AuthorID,
 			ParentCommentID: reply.ParentCommentID,
 			Content:         reply.Content,
-			Author: models.UserResponse{
-				ID:        reply.Author.ID,
-				Username:  reply.Author.Username,
-				Email:     reply.Author.Email,
-				CreatedAt: reply.Author.CreatedAt,
-			},
-			CreatedAt: reply.CreatedAt,
-			UpdatedAt: reply.UpdatedAt,
+			Author:          convertUserToResponse(reply.Author),
+			CreatedAt:       reply.CreatedAt,
+			UpdatedAt:       reply.UpdatedAt,
 		}
 		repliesResponse = append(repliesResponse, replyResponse)
 	}
@@ -335,14 +646,9 @@ func convertCommentToResponse(comment models.Comment) models.CommentResponse {
 		AuthorID:        comment.AuthorID,
 		ParentCommentID: comment.ParentCommentID,
 		Content:         comment.Content,
-		Author: models.UserResponse{
-			ID:        comment.Author.ID,
-			Username:  comment.Author.Username,
-			Email:     comment.Author.Email,
-			CreatedAt: comment.Author.CreatedAt,
-		},
-		Replies:   repliesResponse,
-		CreatedAt: comment.CreatedAt,
-		UpdatedAt: comment.UpdatedAt,
+		Author:          convertUserToResponse(comment.Author),
+		Replies:         repliesResponse,
+		CreatedAt:       comment.CreatedAt,
+		UpdatedAt:       comment.UpdatedAt,
 	}
 }
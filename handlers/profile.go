@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"blog-api/audit"
+	"blog-api/config"
+	"blog-api/models"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var errEmailTaken = errors.New("email already in use")
+
+// UpdateProfile handles editing the authenticated user's profile. Changing the
+// email address requires the current password, or a TOTP or recovery code for
+// accounts without a password that have 2FA enabled, and only takes effect once
+// the new address has been verified.
+func UpdateProfile(c *gin.Context) {
+	// Get user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	userModel := user.(models.User)
+
+	var req models.ProfileUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updates := make(map[string]interface{})
+
+	// Check that a new username is not taken
+	if req.Username != nil && *req.Username != userModel.Username {
+		var count int64
+		config.DB.Model(&models.User{}).Where("username = ? AND id <> ?", *req.Username, userModel.ID).Count(&count)
+		if count > 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "Username is already taken"})
+			return
+		}
+		updates["username"] = *req.Username
+	}
+
+	// A new email address is kept pending until it is verified
+	changeEmail := req.Email != nil && *req.Email != userModel.Email
+	if changeEmail {
+		if userModel.PasswordHash != "" {
+			if req.CurrentPassword == "" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "current_password is required to change email"})
+				return
+			}
+			if err := bcrypt.CompareHashAndPassword([]byte(userModel.PasswordHash), []byte(req.CurrentPassword)); err != nil {
+				recordAccountFailure(c, models.AuditActionProfileUpdate, userModel.ID, "Current password is incorrect")
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Current password is incorrect"})
+				return
+			}
+		} else if userModel.TOTPEnabledAt != nil {
+			// Accounts created through social login or a passkey confirm with their second factor instead
+			if req.Code == "" && req.RecoveryCode == "" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "code or recovery_code is required to change email"})
+				return
+			}
+			if !verifySecondFactor(userModel, req.Code, req.RecoveryCode) {
+				recordAccountFailure(c, models.AuditActionProfileUpdate, userModel.ID, "Invalid two-factor code")
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid two-factor code"})
+				return
+			}
+		}
+
+		var count int64
+		config.DB.Model(&models.User{}).Where("email = ? AND id <> ?", *req.Email, userModel.ID).Count(&count)
+		if count > 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "Email address is already in use"})
+			return
+		}
+		updates["pending_email"] = *req.Email
+	}
+
+	if req.DisplayName != nil {
+		updates["display_name"] = *req.DisplayName
+	}
+	if req.Bio != nil {
+		updates["bio"] = *req.Bio
+	}
+	if req.Website != nil {
+		updates["website"] = *req.Website
+	}
+	if req.AvatarURL != nil {
+		updates["avatar_url"] = *req.AvatarURL
+	}
+
+	if len(updates) > 0 {
+		if err := config.DB.Model(&userModel).Updates(updates).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile"})
+			return
+		}
+
+		fields := make([]string, 0, len(updates))
+		for field := range updates {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		audit.Record(c, audit.Event{
+			Action:     models.AuditActionProfileUpdate,
+			TargetType: models.AuditTargetUser,
+			TargetID:   userModel.ID,
+			Reason:     "Changed " + strings.Join(fields, ", "),
+		})
+	}
+
+	message := "Profile updated successfully"
+	if changeEmail {
+		if err := sendEmailChangeVerification(userModel, *req.Email); err != nil {
+			log.Printf("Failed to send email change verification to user %s: %v", userModel.ID, err)
+		}
+		message = "Profile updated successfully, check your new email address to confirm the change"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"user":    convertUserToProfileResponse(userModel),
+	})
+}
+
+// GetUserProfile handles getting a user's public profile and posts by username
+func GetUserProfile(c *gin.Context) {
+	username := c.Param("username")
+
+	var user models.User
+	if err := config.DB.Where("username = ? AND anonymized_at IS NULL", username).First(&user).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	// Get pagination parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	offset := (page - 1) * limit
+
+	var posts []models.Post
+	var total int64
+
+	// Count user's published posts
+	publishedPosts(config.DB.Model(&models.Post{})).Where("author_id = ?", user.ID).Count(&total)
+
+	if err := publishedPosts(config.DB.Preload("Author")).
+		Preload("Likes").
+		Where("author_id = ?", user.ID).
+		Offset(offset).
+		Limit(limit).
+		Order("published_at DESC").
+		Find(&posts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch posts"})
+		return
+	}
+
+	// Convert to response format
+	var postsResponse []models.PostResponse
+	for _, post := range posts {
+		postsResponse = append(postsResponse, convertPostToResponse(post))
+	}
+
+	profile := convertUserToPublicProfileResponse(user)
+	config.DB.Model(&models.Follow{}).Where("following_id = ?", user.ID).Count(&profile.FollowersCount)
+	config.DB.Model(&models.Follow{}).Where("follower_id = ?", user.ID).Count(&profile.FollowingCount)
+
+	// Tell signed-in viewers whether they follow this user
+	if viewer, exists := c.Get("user"); exists {
+		following := isFollowing(viewer.(models.User).ID, user.ID)
+		profile.IsFollowing = &following
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user":  profile,
+		"posts": postsResponse,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+// convertUserToResponse converts a user to the public summary embedded in other resources
+func convertUserToResponse(user models.User) models.UserResponse {
+	return models.UserResponse{
+		ID:          user.ID,
+		Username:    user.Username,
+		DisplayName: user.DisplayName,
+		AvatarURL:   user.AvatarURL,
+		CreatedAt:   user.CreatedAt,
+	}
+}
+
+// convertUserToProfileResponse converts a user to their own, private profile
+func convertUserToProfileResponse(user models.User) models.ProfileResponse {
+	return models.ProfileResponse{
+		ID:                  user.ID,
+		Username:            user.Username,
+		Email:               user.Email,
+		PendingEmail:        user.PendingEmail,
+		EmailVerifiedAt:     user.EmailVerifiedAt,
+		Role:                user.Role,
+		TwoFactorEnabled:    user.TOTPEnabledAt != nil,
+		DisplayName:         user.DisplayName,
+		Bio:                 user.Bio,
+		Website:             user.Website,
+		AvatarURL:           user.AvatarURL,
+		DeletionScheduledAt: user.DeletionScheduledAt,
+		CreatedAt:           user.CreatedAt,
+		UpdatedAt:           user.UpdatedAt,
+	}
+}
+
+// convertUserToPublicProfileResponse converts a user to the profile shown to others
+func convertUserToPublicProfileResponse(user models.User) models.PublicProfileResponse {
+	return models.PublicProfileResponse{
+		ID:          user.ID,
+		Username:    user.Username,
+		DisplayName: user.DisplayName,
+		Bio:         user.Bio,
+		Website:     user.Website,
+		AvatarURL:   user.AvatarURL,
+		CreatedAt:   user.CreatedAt,
+	}
+}
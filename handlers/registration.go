@@ -0,0 +1,285 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"blog-api/audit"
+	"blog-api/config"
+	"blog-api/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	errRegistrationClosed    = errors.New("registration is closed")
+	errInviteRequired        = errors.New("invite code required")
+	errInviteInvalid         = errors.New("invalid or expired invite code")
+	errEmailDomainNotAllowed = errors.New("email domain not allowed")
+)
+
+// GetRegistrationSettings handles telling clients how registration currently works
+func GetRegistrationSettings(c *gin.Context) {
+	mode := config.RegistrationMode()
+
+	response := gin.H{
+		"mode":            mode,
+		"invite_required": mode == config.RegistrationInviteOnly,
+	}
+	if mode == config.RegistrationDomain {
+		response["allowed_domains"] = config.RegistrationAllowedDomains()
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateInvite handles an admin issuing an invite code. The code is only
+// returned in this response.
+func CreateInvite(c *gin.Context) {
+	// Get acting user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	actingUser := user.(models.User)
+
+	var req models.InviteCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expires_at must be in the future"})
+		return
+	}
+
+	code, err := generateInviteCode()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate invite code"})
+		return
+	}
+
+	invite := models.Invite{
+		ID:          uuid.New().String(),
+		CodeHash:    hashToken(normalizeInviteCode(code)),
+		Prefix:      code[:4],
+		Role:        req.Role,
+		Note:        req.Note,
+		MaxUses:     req.MaxUses,
+		ExpiresAt:   req.ExpiresAt,
+		CreatedByID: actingUser.ID,
+	}
+	if invite.Role == "" {
+		invite.Role = config.DefaultUserRole()
+	}
+	if invite.MaxUses == 0 {
+		invite.MaxUses = 1
+	}
+
+	if err := config.DB.Create(&invite).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invite"})
+		return
+	}
+
+	audit.Record(c, audit.Event{
+		Action:     models.AuditActionAdminInviteCreate,
+		TargetType: models.AuditTargetInvite,
+		TargetID:   invite.ID,
+		Reason:     "role " + invite.Role,
+	})
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Invite created successfully. Copy the code now, it will not be shown again",
+		"invite":  convertInviteToResponse(invite),
+		"code":    code,
+	})
+}
+
+// ListInvites handles listing invite codes, newest first
+func ListInvites(c *gin.Context) {
+	query := config.DB.Model(&models.Invite{})
+
+	// Only show invites that can still be redeemed
+	if c.Query("active") == "true" {
+		query = query.Where("revoked_at IS NULL AND uses < max_uses AND (expires_at IS NULL OR expires_at > ?)", time.Now())
+	}
+
+	var invites []models.Invite
+	if err := query.Order("created_at DESC").Find(&invites).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch invites"})
+		return
+	}
+
+	// Convert to response format
+	invitesResponse := make([]models.InviteResponse, 0, len(invites))
+	for _, invite := range invites {
+		invitesResponse = append(invitesResponse, convertInviteToResponse(invite))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"invites": invitesResponse,
+	})
+}
+
+// RevokeInvite handles an admin revoking an invite code
+func RevokeInvite(c *gin.Context) {
+	var invite models.Invite
+	if err := config.DB.First(&invite, "id = ?", c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invite not found"})
+		return
+	}
+
+	if invite.RevokedAt == nil {
+		if err := config.DB.Model(&invite).Update("revoked_at", time.Now()).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke invite"})
+			return
+		}
+
+		audit.Record(c, audit.Event{
+			Action:     models.AuditActionAdminInviteRevoke,
+			TargetType: models.AuditTargetInvite,
+			TargetID:   invite.ID,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Invite revoked successfully",
+	})
+}
+
+// checkRegistration decides whether an account may be created for the email
+// under the current registration mode. It returns the invite to redeem, if a
+// valid code was given. A valid invite also admits addresses outside the
+// allowed domains.
+func checkRegistration(email, inviteCode string) (*models.Invite, error) {
+	mode := config.RegistrationMode()
+	if mode == config.RegistrationClosed {
+		return nil, errRegistrationClosed
+	}
+
+	var invite *models.Invite
+	if inviteCode != "" {
+		var found models.Invite
+		if err := config.DB.Where("code_hash = ?", hashToken(normalizeInviteCode(inviteCode))).First(&found).Error; err != nil ||
+			!found.IsActive() {
+			return nil, errInviteInvalid
+		}
+		invite = &found
+	}
+
+	switch mode {
+	case config.RegistrationInviteOnly:
+		if invite == nil {
+			return nil, errInviteRequired
+		}
+	case config.RegistrationDomain:
+		if invite == nil && !emailDomainAllowed(email) {
+			return nil, errEmailDomainNotAllowed
+		}
+	}
+
+	return invite, nil
+}
+
+// redeemInvite uses up one use of an invite. Only as many concurrent
+// registrations as the invite has uses left can succeed.
+func redeemInvite(tx *gorm.DB, inviteID string) error {
+	result := tx.Model(&models.Invite{}).
+		Where("id = ? AND revoked_at IS NULL AND uses < max_uses AND (expires_at IS NULL OR expires_at > ?)", inviteID, time.Now()).
+		Update("uses", gorm.Expr("uses + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errInviteInvalid
+	}
+	return nil
+}
+
+// registrationRole returns the role for a new account
+func registrationRole(invite *models.Invite) string {
+	if invite != nil && models.IsValidRole(invite.Role) {
+		return invite.Role
+	}
+	return config.DefaultUserRole()
+}
+
+// respondRegistrationError writes the response for a refused registration and
+// reports whether err was a registration error
+func respondRegistrationError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, errRegistrationClosed):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Registration is closed"})
+	case errors.Is(err, errInviteRequired):
+		c.JSON(http.StatusForbidden, gin.H{"error": "An invite code is required to register"})
+	case errors.Is(err, errInviteInvalid):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Invalid or expired invite code"})
+	case errors.Is(err, errEmailDomainNotAllowed):
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":           "Registration is limited to email addresses at the allowed domains",
+			"allowed_domains": config.RegistrationAllowedDomains(),
+		})
+	default:
+		return false
+	}
+	return true
+}
+
+// emailDomainAllowed reports whether the email address is at one of the allowed domains
+func emailDomainAllowed(email string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+
+	domain := strings.ToLower(email[at+1:])
+	for _, allowed := range config.RegistrationAllowedDomains() {
+		if domain == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// generateInviteCode returns a random code formatted as three groups of four characters
+func generateInviteCode() (string, error) {
+	buf := make([]byte, 10)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	code := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(buf)[:12]
+	return code[:4] + "-" + code[4:8] + "-" + code[8:], nil
+}
+
+// normalizeInviteCode strips formatting so that codes match regardless of case or dashes
+func normalizeInviteCode(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	return strings.ReplaceAll(code, "-", "")
+}
+
+// convertInviteToResponse converts an invite to response format
+func convertInviteToResponse(invite models.Invite) models.InviteResponse {
+	return models.InviteResponse{
+		ID:          invite.ID,
+		Prefix:      invite.Prefix,
+		Role:        invite.Role,
+		Note:        invite.Note,
+		MaxUses:     invite.MaxUses,
+		Uses:        invite.Uses,
+		ExpiresAt:   invite.ExpiresAt,
+		RevokedAt:   invite.RevokedAt,
+		Active:      invite.IsActive(),
+		CreatedByID: invite.CreatedByID,
+		CreatedAt:   invite.CreatedAt,
+	}
+}
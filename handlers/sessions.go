@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"blog-api/audit"
+	"blog-api/config"
+	"blog-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListSessions handles listing the devices the authenticated user is signed in on
+func ListSessions(c *gin.Context) {
+	// Get user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	userModel := user.(models.User)
+	currentSessionID := c.GetString("session_id")
+
+	var sessions []models.Session
+	if err := config.DB.Where("user_id = ? AND revoked_at IS NULL", userModel.ID).
+		Order("last_seen_at DESC").Find(&sessions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+		return
+	}
+
+	// Convert to response format
+	sessionsResponse := make([]models.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		sessionsResponse = append(sessionsResponse, convertSessionToResponse(session, currentSessionID))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"sessions": sessionsResponse,
+	})
+}
+
+// RevokeSession handles signing out one of the authenticated user's sessions
+func RevokeSession(c *gin.Context) {
+	// Get user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	userModel := user.(models.User)
+
+	var session models.Session
+	if err := config.DB.Where("id = ? AND user_id = ?", c.Param("id"), userModel.ID).First(&session).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+
+	if err := revokeSession(config.DB, session.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		return
+	}
+
+	audit.Record(c, audit.Event{
+		Action:     models.AuditActionSessionRevoke,
+		TargetType: models.AuditTargetSession,
+		TargetID:   session.ID,
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Session revoked successfully",
+	})
+}
+
+// RevokeOtherSessions handles signing out every session except the current one
+func RevokeOtherSessions(c *gin.Context) {
+	// Get user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	userModel := user.(models.User)
+
+	currentSessionID := c.GetString("session_id")
+	if currentSessionID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Current session unknown"})
+		return
+	}
+
+	if err := revokeUserSessions(config.DB, userModel.ID, currentSessionID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+		return
+	}
+
+	audit.Record(c, audit.Event{
+		Action:     models.AuditActionSessionRevoke,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userModel.ID,
+		Reason:     "All other sessions",
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Signed out of all other sessions",
+	})
+}
+
+// describeDevice returns a short human readable name such as "Chrome on Windows" for a user agent
+func describeDevice(userAgent string) string {
+	if userAgent == "" {
+		return "Unknown device"
+	}
+
+	ua := strings.ToLower(userAgent)
+
+	// Order matters: many user agents mention several browsers and platforms
+	browser := "Unknown browser"
+	for _, candidate := range []struct{ token, name string }{
+		{"edg/", "Edge"},
+		{"opr/", "Opera"},
+		{"firefox/", "Firefox"},
+		{"chrome/", "Chrome"},
+		{"safari/", "Safari"},
+		{"curl/", "curl"},
+		{"postmanruntime/", "Postman"},
+		{"okhttp/", "OkHttp"},
+		{"go-http-client/", "Go HTTP client"},
+	} {
+		if strings.Contains(ua, candidate.token) {
+			browser = candidate.name
+			break
+		}
+	}
+
+	platform := ""
+	for _, candidate := range []struct{ token, name string }{
+		{"iphone", "iOS"},
+		{"ipad", "iPadOS"},
+		{"android", "Android"},
+		{"windows", "Windows"},
+		{"mac os x", "macOS"},
+		{"cros", "ChromeOS"},
+		{"linux", "Linux"},
+	} {
+		if strings.Contains(ua, candidate.token) {
+			platform = candidate.name
+			break
+		}
+	}
+
+	if platform == "" {
+		return browser
+	}
+	return browser + " on " + platform
+}
+
+// convertSessionToResponse converts a session to response format
+func convertSessionToResponse(session models.Session, currentSessionID string) models.SessionResponse {
+	return models.SessionResponse{
+		ID:         session.ID,
+		DeviceName: session.DeviceName,
+		UserAgent:  session.UserAgent,
+		IPAddress:  session.IPAddress,
+		Current:    session.ID == currentSessionID,
+		LastSeenAt: session.LastSeenAt,
+		CreatedAt:  session.CreatedAt,
+	}
+}
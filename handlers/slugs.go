@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"blog-api/config"
+	"blog-api/models"
+	"blog-api/slug"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// errSlugTaken is returned when a slug already belongs to another post
+var errSlugTaken = errors.New("slug is already in use")
+
+// maxSlugAttempts bounds the numbered suffixes tried before falling back to a random one
+const maxSlugAttempts = 100
+
+// GetPostBySlug handles getting a single post by its slug. Old slugs redirect
+// to the post's current slug.
+func GetPostBySlug(c *gin.Context) {
+	requested := c.Param("slug")
+
+	var postSlug models.PostSlug
+	if err := config.DB.Where("slug = ?", requested).First(&postSlug).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		return
+	}
+
+	var post models.Post
+	if err := config.DB.First(&post, "id = ?", postSlug.PostID).Error; err != nil || !canViewPost(c, post) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		return
+	}
+
+	if post.Slug != requested {
+		c.Redirect(http.StatusMovedPermanently, "/api/v1/posts/by-slug/"+url.PathEscape(post.Slug))
+		return
+	}
+
+	respondWithPost(c, post.ID)
+}
+
+// checkCustomSlug responds with 400 when a custom slug is given but is not a
+// well-formed slug. It reports whether the request may go ahead.
+func checkCustomSlug(c *gin.Context, custom string) bool {
+	if custom != "" && !slug.Valid(custom) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Slug may only contain lowercase letters, digits, and single hyphens"})
+		return false
+	}
+	return true
+}
+
+// setPostSlug gives the post the custom slug, or a slug generated from its
+// title when custom is empty. The previous slug stays reserved for the post.
+// It returns errSlugTaken when the custom slug belongs to another post.
+func setPostSlug(tx *gorm.DB, post *models.Post, custom string) error {
+	if custom != "" {
+		return claimPostSlug(tx, post, custom)
+	}
+
+	base := slug.Make(post.Title)
+	if base == "" {
+		base = "post"
+	}
+
+	// Try the plain slug first, then numbered ones: hello-world, hello-world-2, ...
+	for n := 1; n <= maxSlugAttempts; n++ {
+		candidate := base
+		if n > 1 {
+			candidate = withSlugSuffix(base, strconv.Itoa(n))
+		}
+		if err := claimPostSlug(tx, post, candidate); !errors.Is(err, errSlugTaken) {
+			return err
+		}
+	}
+	return claimPostSlug(tx, post, withSlugSuffix(base, strings.ReplaceAll(uuid.New().String(), "-", "")[:8]))
+}
+
+// claimPostSlug reserves s for the post and makes it the post's current slug.
+// A slug the post had before is simply reused.
+func claimPostSlug(tx *gorm.DB, post *models.Post, s string) error {
+	var existing models.PostSlug
+	err := tx.Where("slug = ?", s).First(&existing).Error
+	switch {
+	case err == nil:
+		if existing.PostID != post.ID {
+			return errSlugTaken
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		// The unique index settles races with requests claiming the same slug
+		err := tx.Create(&models.PostSlug{ID: uuid.New().String(), PostID: post.ID, Slug: s}).Error
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return errSlugTaken
+		}
+		if err != nil {
+			return err
+		}
+	default:
+		return err
+	}
+
+	if post.Slug == s {
+		return nil
+	}
+	post.Slug = s
+	return tx.Model(&models.Post{}).Where("id = ?", post.ID).Update("slug", s).Error
+}
+
+// withSlugSuffix appends -suffix to base, shortening base to stay within slug.MaxLength
+func withSlugSuffix(base, suffix string) string {
+	if maxBase := slug.MaxLength - len(suffix) - 1; len(base) > maxBase {
+		base = strings.TrimRight(base[:maxBase], "-")
+	}
+	return base + "-" + suffix
+}
+
+// BackfillPostSlugs gives a slug to posts created before slugs were introduced
+func BackfillPostSlugs() {
+	var posts []models.Post
+	if err := config.DB.Where("slug = '' OR slug IS NULL").Find(&posts).Error; err != nil {
+		log.Printf("Failed to load posts without a slug: %v", err)
+		return
+	}
+
+	for i := range posts {
+		err := config.DB.Transaction(func(tx *gorm.DB) error {
+			return setPostSlug(tx, &posts[i], "")
+		})
+		if err != nil {
+			log.Printf("Failed to assign a slug to post %s: %v", posts[i].ID, err)
+		}
+	}
+}
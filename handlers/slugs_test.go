@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"blog-api/slug"
+)
+
+func TestWithSlugSuffix(t *testing.T) {
+	long := strings.Repeat("a", slug.MaxLength)
+
+	tests := []struct {
+		name   string
+		base   string
+		suffix string
+		want   string
+	}{
+		{"short base", "hello-world", "2", "hello-world-2"},
+		{"counter", "hello-world", "10", "hello-world-10"},
+		{"random suffix", "post", "1a2b3c4d", "post-1a2b3c4d"},
+		{"base at the limit", long, "2", strings.Repeat("a", slug.MaxLength-2) + "-2"},
+		{"base just fits", strings.Repeat("a", slug.MaxLength-2), "2", strings.Repeat("a", slug.MaxLength-2) + "-2"},
+		{"cut leaves a trailing hyphen", strings.Repeat("a", slug.MaxLength-4) + "-bbb", "10", strings.Repeat("a", slug.MaxLength-4) + "-10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := withSlugSuffix(tt.base, tt.suffix)
+			if got != tt.want {
+				t.Errorf("withSlugSuffix() = %q, want %q", got, tt.want)
+			}
+			if !slug.Valid(got) {
+				t.Errorf("withSlugSuffix() = %q, which is not a valid slug", got)
+			}
+		})
+	}
+
+	// The numbered candidates tried for a colliding title are all distinct
+	seen := map[string]bool{long: true}
+	for n := 2; n <= maxSlugAttempts; n++ {
+		candidate := withSlugSuffix(long, strconv.Itoa(n))
+		if seen[candidate] {
+			t.Fatalf("withSlugSuffix() repeated %q", candidate)
+		}
+		if !slug.Valid(candidate) {
+			t.Fatalf("withSlugSuffix() = %q, which is not a valid slug", candidate)
+		}
+		seen[candidate] = true
+	}
+}
@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"blog-api/auth"
+	"blog-api/config"
+	"blog-api/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	errRefreshTokenInvalid = errors.New("invalid refresh token")
+	errRefreshTokenExpired = errors.New("refresh token expired")
+	errRefreshTokenReused  = errors.New("refresh token reuse detected")
+	errUserTokenInvalid    = errors.New("invalid or expired token")
+)
+
+// tokenPair is the credential set returned to a client after authentication
+type tokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int64
+}
+
+// issueTokens starts a new session for the requesting device and returns its
+// access token and first refresh token
+func issueTokens(c *gin.Context, userID string) (*tokenPair, error) {
+	userAgent := c.Request.UserAgent()
+	if len(userAgent) > 500 {
+		userAgent = userAgent[:500]
+	}
+
+	session := models.Session{
+		ID:         uuid.New().String(),
+		UserID:     userID,
+		DeviceName: describeDevice(userAgent),
+		UserAgent:  userAgent,
+		IPAddress:  c.ClientIP(),
+		LastSeenAt: time.Now(),
+	}
+	if err := config.DB.Create(&session).Error; err != nil {
+		return nil, err
+	}
+
+	accessToken, err := generateToken(userID, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, refreshToken, err := createRefreshToken(config.DB, userID, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(config.AccessTokenTTL().Seconds()),
+	}, nil
+}
+
+// rotateRefreshToken exchanges a refresh token for a new token pair. Presenting
+// a token that has already been rotated or revoked revokes its whole session.
+func rotateRefreshToken(c *gin.Context, rawToken string) (*tokenPair, error) {
+	var current models.RefreshToken
+	if err := config.DB.Where("token_hash = ?", hashToken(rawToken)).First(&current).Error; err != nil {
+		return nil, errRefreshTokenInvalid
+	}
+
+	if current.RevokedAt != nil {
+		revokeSession(config.DB, current.FamilyID)
+		return nil, errRefreshTokenReused
+	}
+
+	if time.Now().After(current.ExpiresAt) {
+		return nil, errRefreshTokenExpired
+	}
+
+	// Refresh tokens stop working once their session has been revoked
+	var session models.Session
+	if err := config.DB.First(&session, "id = ? AND user_id = ?", current.FamilyID, current.UserID).Error; err != nil ||
+		session.RevokedAt != nil {
+		return nil, errRefreshTokenInvalid
+	}
+
+	var newRefreshToken string
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// Only one concurrent rotation may win; the loser is treated as reuse
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", current.ID).
+			Update("revoked_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errRefreshTokenReused
+		}
+
+		replacement, rawToken, err := createRefreshToken(tx, current.UserID, current.FamilyID)
+		if err != nil {
+			return err
+		}
+		newRefreshToken = rawToken
+
+		return tx.Model(&models.RefreshToken{}).Where("id = ?", current.ID).Update("replaced_by", replacement.ID).Error
+	})
+	if err != nil {
+		if errors.Is(err, errRefreshTokenReused) {
+			revokeSession(config.DB, current.FamilyID)
+		}
+		return nil, err
+	}
+
+	// Record where the session was last used
+	config.DB.Model(&models.Session{}).Where("id = ?", current.FamilyID).Updates(map[string]interface{}{
+		"last_seen_at": time.Now(),
+		"ip_address":   c.ClientIP(),
+	})
+
+	accessToken, err := generateToken(current.UserID, current.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: newRefreshToken,
+		ExpiresIn:    int64(config.AccessTokenTTL().Seconds()),
+	}, nil
+}
+
+// createRefreshToken stores a new refresh token in the given family and returns it with its raw value
+func createRefreshToken(db *gorm.DB, userID, familyID string) (*models.RefreshToken, string, error) {
+	rawToken, err := generateOpaqueToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	refreshToken := models.RefreshToken{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(rawToken),
+		ExpiresAt: time.Now().Add(config.RefreshTokenTTL()),
+	}
+
+	if err := db.Create(&refreshToken).Error; err != nil {
+		return nil, "", err
+	}
+
+	return &refreshToken, rawToken, nil
+}
+
+// revokeSession ends a session: its access tokens stop being accepted and
+// every active refresh token descended from the same login is revoked
+func revokeSession(db *gorm.DB, sessionID string) error {
+	now := time.Now()
+	if err := db.Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", sessionID).
+		Update("revoked_at", now).Error; err != nil {
+		return err
+	}
+
+	return db.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", sessionID).
+		Update("revoked_at", now).Error
+}
+
+// revokeUserSessions ends every session of a user except the one with keepSessionID, if given
+func revokeUserSessions(db *gorm.DB, userID string, keepSessionID string) error {
+	now := time.Now()
+
+	sessions := db.Model(&models.Session{}).Where("user_id = ? AND revoked_at IS NULL", userID)
+	refreshTokens := db.Model(&models.RefreshToken{}).Where("user_id = ? AND revoked_at IS NULL", userID)
+	if keepSessionID != "" {
+		sessions = sessions.Where("id <> ?", keepSessionID)
+		refreshTokens = refreshTokens.Where("family_id <> ?", keepSessionID)
+	}
+
+	if err := sessions.Update("revoked_at", now).Error; err != nil {
+		return err
+	}
+	return refreshTokens.Update("revoked_at", now).Error
+}
+
+// createUserToken stores a single-use token for the given purpose and returns its raw value.
+// Any earlier unused token with the same purpose is invalidated.
+func createUserToken(db *gorm.DB, userID, purpose string, ttl time.Duration) (string, error) {
+	rawToken, err := generateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.UserToken{}).
+			Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+			Update("used_at", time.Now()).Error; err != nil {
+			return err
+		}
+
+		userToken := models.UserToken{
+			ID:        uuid.New().String(),
+			UserID:    userID,
+			Purpose:   purpose,
+			TokenHash: hashToken(rawToken),
+			ExpiresAt: time.Now().Add(ttl),
+		}
+		return tx.Create(&userToken).Error
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return rawToken, nil
+}
+
+// consumeUserToken marks a single-use token as used and returns it. It fails if
+// the token is unknown, has none of the given purposes, is expired, or was already used.
+func consumeUserToken(db *gorm.DB, rawToken string, purposes ...string) (*models.UserToken, error) {
+	var userToken models.UserToken
+	if err := db.Where("token_hash = ? AND purpose IN ?", hashToken(rawToken), purposes).First(&userToken).Error; err != nil {
+		return nil, errUserTokenInvalid
+	}
+
+	if userToken.UsedAt != nil || time.Now().After(userToken.ExpiresAt) {
+		return nil, errUserTokenInvalid
+	}
+
+	// Guard against the same token being consumed twice concurrently
+	result := db.Model(&models.UserToken{}).
+		Where("id = ? AND used_at IS NULL", userToken.ID).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errUserTokenInvalid
+	}
+
+	return &userToken, nil
+}
+
+// generateToken generates a short-lived JWT access token for the user's session
+func generateToken(userID, sessionID string) (string, error) {
+	return auth.SignAccessToken(jwt.MapClaims{
+		"sub":     userID,
+		"user_id": userID,
+		"sid":     sessionID,
+	}, config.AccessTokenTTL())
+}
+
+// generateOpaqueToken returns a random URL-safe token suitable for handing to clients
+func generateOpaqueToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hashToken returns the SHA-256 digest used to store opaque tokens at rest
+func hashToken(rawToken string) string {
+	sum := sha256.Sum256([]byte(rawToken))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,366 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"net/http"
+	"strings"
+	"time"
+
+	"blog-api/config"
+	"blog-api/models"
+	"blog-api/totp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	// recoveryCodeCount is the number of recovery codes issued at a time
+	recoveryCodeCount = 10
+	// maxTwoFactorAttempts is the number of wrong codes accepted per login challenge
+	maxTwoFactorAttempts = 5
+)
+
+// SetupTwoFactor handles starting TOTP enrollment. It stores a new secret that
+// only takes effect once confirmed with a valid code.
+func SetupTwoFactor(c *gin.Context) {
+	// Get user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	userModel := user.(models.User)
+
+	if userModel.TOTPEnabledAt != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate secret"})
+		return
+	}
+
+	if err := config.DB.Model(&userModel).Update("totp_secret", secret).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start two-factor setup"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Scan the otpauth URI with your authenticator app, then confirm with a code",
+		"secret":      secret,
+		"otpauth_uri": totp.URI(config.TOTPIssuer(), userModel.Email, secret),
+	})
+}
+
+// ConfirmTwoFactor handles finishing TOTP enrollment and returns recovery codes
+func ConfirmTwoFactor(c *gin.Context) {
+	// Get user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	userModel := user.(models.User)
+
+	var req models.TwoFactorConfirmRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if userModel.TOTPEnabledAt != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+	if userModel.TOTPSecret == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor setup has not been started"})
+		return
+	}
+
+	step, ok := totp.Validate(userModel.TOTPSecret, req.Code, time.Now())
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid two-factor code"})
+		return
+	}
+
+	var recoveryCodes []string
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&userModel).Updates(map[string]interface{}{
+			"totp_enabled_at":     time.Now(),
+			"totp_last_used_step": step,
+		}).Error; err != nil {
+			return err
+		}
+
+		var err error
+		recoveryCodes, err = replaceRecoveryCodes(tx, userModel.ID)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enable two-factor authentication"})
+		return
+	}
+
+	recordAccountEvent(c, models.AuditActionTwoFactorEnable, userModel.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Two-factor authentication enabled. Store the recovery codes somewhere safe, they will not be shown again",
+		"recovery_codes": recoveryCodes,
+	})
+}
+
+// DisableTwoFactor handles turning off TOTP. It requires a current TOTP or
+// recovery code, and the password for accounts that have one. Accounts created
+// through social login or a passkey have no password and rely on the code alone.
+func DisableTwoFactor(c *gin.Context) {
+	// Get user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	userModel := user.(models.User)
+
+	var req models.TwoFactorDisableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if userModel.TOTPEnabledAt == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor authentication is not enabled"})
+		return
+	}
+
+	if userModel.PasswordHash != "" {
+		if err := bcrypt.CompareHashAndPassword([]byte(userModel.PasswordHash), []byte(req.Password)); err != nil {
+			recordAccountFailure(c, models.AuditActionTwoFactorDisable, userModel.ID, "Password is incorrect")
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Password is incorrect"})
+			return
+		}
+	}
+
+	if !verifySecondFactor(userModel, req.Code, req.RecoveryCode) {
+		recordAccountFailure(c, models.AuditActionTwoFactorDisable, userModel.ID, "Invalid two-factor code")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid two-factor code"})
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&userModel).Updates(map[string]interface{}{
+			"totp_secret":         "",
+			"totp_enabled_at":     nil,
+			"totp_last_used_step": 0,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userModel.ID).Delete(&models.RecoveryCode{}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable two-factor authentication"})
+		return
+	}
+
+	recordAccountEvent(c, models.AuditActionTwoFactorDisable, userModel.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Two-factor authentication disabled",
+	})
+}
+
+// RegenerateRecoveryCodes handles replacing all recovery codes with a new set
+func RegenerateRecoveryCodes(c *gin.Context) {
+	// Get user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	userModel := user.(models.User)
+
+	var req models.TwoFactorRecoveryCodesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if userModel.TOTPEnabledAt == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor authentication is not enabled"})
+		return
+	}
+
+	if !verifySecondFactor(userModel, req.Code, "") {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid two-factor code"})
+		return
+	}
+
+	recoveryCodes, err := replaceRecoveryCodes(config.DB, userModel.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate recovery codes"})
+		return
+	}
+
+	recordAccountEvent(c, models.AuditActionRecoveryCodes, userModel.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Recovery codes regenerated, previous codes no longer work",
+		"recovery_codes": recoveryCodes,
+	})
+}
+
+// VerifyTwoFactorLogin handles the second login step, exchanging a challenge
+// token and a TOTP or recovery code for access and refresh tokens
+func VerifyTwoFactorLogin(c *gin.Context) {
+	var req models.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Code == "" && req.RecoveryCode == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "code or recovery_code is required"})
+		return
+	}
+
+	// Find challenge
+	var challenge models.UserToken
+	if err := config.DB.Where("token_hash = ? AND purpose = ?", hashToken(req.ChallengeToken), models.TokenPurposeTwoFactorLogin).
+		First(&challenge).Error; err != nil ||
+		challenge.UsedAt != nil || time.Now().After(challenge.ExpiresAt) || challenge.Attempts >= maxTwoFactorAttempts {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired challenge, please log in again"})
+		return
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, "id = ?", challenge.UserID).Error; err != nil || user.TOTPEnabledAt == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired challenge, please log in again"})
+		return
+	}
+
+	if !verifySecondFactor(user, req.Code, req.RecoveryCode) {
+		config.DB.Model(&challenge).Update("attempts", gorm.Expr("attempts + 1"))
+		recordAccountFailure(c, models.AuditActionLoginTwoFactor, user.ID, "Invalid two-factor code")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid two-factor code"})
+		return
+	}
+
+	// The challenge can only be exchanged once
+	if _, err := consumeUserToken(config.DB, req.ChallengeToken, models.TokenPurposeTwoFactorLogin); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired challenge, please log in again"})
+		return
+	}
+
+	// Generate access and refresh tokens
+	tokens, err := issueTokens(c, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	recordAccountEvent(c, models.AuditActionLoginTwoFactor, user.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Login successful",
+		"user":          convertUserToProfileResponse(user),
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+	})
+}
+
+// startTwoFactorChallenge issues the short-lived token returned by the password step of login
+func startTwoFactorChallenge(c *gin.Context, user models.User) {
+	challengeToken, err := createUserToken(config.DB, user.ID, models.TokenPurposeTwoFactorLogin, config.TwoFactorChallengeTTL())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start two-factor login"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":             "Two-factor authentication required",
+		"two_factor_required": true,
+		"challenge_token":     challengeToken,
+		"expires_in":          int64(config.TwoFactorChallengeTTL().Seconds()),
+	})
+}
+
+// verifySecondFactor checks a TOTP code, or failing that a recovery code, for the user.
+// Accepted TOTP codes and recovery codes cannot be used again.
+func verifySecondFactor(user models.User, code, recoveryCode string) bool {
+	if code != "" {
+		step, ok := totp.Validate(user.TOTPSecret, code, time.Now())
+		if !ok {
+			return false
+		}
+
+		// Reject a code from a time step that was already used
+		result := config.DB.Model(&models.User{}).
+			Where("id = ? AND totp_last_used_step < ?", user.ID, step).
+			Update("totp_last_used_step", step)
+		return result.Error == nil && result.RowsAffected == 1
+	}
+
+	if recoveryCode != "" {
+		result := config.DB.Model(&models.RecoveryCode{}).
+			Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user.ID, hashToken(normalizeRecoveryCode(recoveryCode))).
+			Update("used_at", time.Now())
+		return result.Error == nil && result.RowsAffected == 1
+	}
+
+	return false
+}
+
+// replaceRecoveryCodes deletes the user's recovery codes and stores a new set, returning the raw codes
+func replaceRecoveryCodes(db *gorm.DB, userID string) ([]string, error) {
+	if err := db.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+
+		recoveryCode := models.RecoveryCode{
+			ID:       uuid.New().String(),
+			UserID:   userID,
+			CodeHash: hashToken(normalizeRecoveryCode(code)),
+		}
+		if err := db.Create(&recoveryCode).Error; err != nil {
+			return nil, err
+		}
+
+		codes = append(codes, code)
+	}
+
+	return codes, nil
+}
+
+// generateRecoveryCode returns a random code formatted as two groups of five characters
+func generateRecoveryCode() (string, error) {
+	buf := make([]byte, 10)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	code := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(buf))[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+// normalizeRecoveryCode strips formatting so that codes match regardless of case or dashes
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.ReplaceAll(code, "-", "")
+}
@@ -0,0 +1,491 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"blog-api/audit"
+	"blog-api/config"
+	"blog-api/models"
+	"blog-api/textutil"
+	"blog-api/webauthn"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var errWebAuthnChallengeInvalid = errors.New("invalid or expired WebAuthn challenge")
+
+// BeginPasskeyRegistration handles starting the registration of a passkey for
+// the authenticated user. The response is passed to navigator.credentials.create().
+func BeginPasskeyRegistration(c *gin.Context) {
+	// Get user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	userModel := user.(models.User)
+
+	challenge, err := createWebAuthnChallenge(&userModel.ID, models.WebAuthnPurposeRegistration)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start passkey registration"})
+		return
+	}
+
+	// Keep the same authenticator from being registered twice
+	var credentials []models.WebAuthnCredential
+	config.DB.Where("user_id = ?", userModel.ID).Find(&credentials)
+
+	pubKeyCredParams := make([]gin.H, 0, len(webauthn.SupportedAlgorithms))
+	for _, algorithm := range webauthn.SupportedAlgorithms {
+		pubKeyCredParams = append(pubKeyCredParams, gin.H{"type": "public-key", "alg": algorithm})
+	}
+
+	displayName := userModel.DisplayName
+	if displayName == "" {
+		displayName = userModel.Username
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"publicKey": gin.H{
+			"rp": gin.H{
+				"id":   config.WebAuthnRPID(),
+				"name": config.WebAuthnRPName(),
+			},
+			"user": gin.H{
+				"id":          webauthn.EncodeBase64([]byte(userModel.ID)),
+				"name":        userModel.Email,
+				"displayName": displayName,
+			},
+			"challenge":          challenge,
+			"pubKeyCredParams":   pubKeyCredParams,
+			"timeout":            config.WebAuthnChallengeTTL().Milliseconds(),
+			"excludeCredentials": credentialDescriptors(credentials),
+			"authenticatorSelection": gin.H{
+				"residentKey":      "preferred",
+				"userVerification": userVerificationRequirement(),
+			},
+			"attestation": "none",
+		},
+	})
+}
+
+// FinishPasskeyRegistration handles storing the passkey created by the browser
+func FinishPasskeyRegistration(c *gin.Context) {
+	// Get user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	userModel := user.(models.User)
+
+	var req models.WebAuthnRegisterFinishRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	clientDataJSON, err := webauthn.DecodeBase64(req.Credential.Response.ClientDataJSON)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid clientDataJSON encoding"})
+		return
+	}
+	attestationObject, err := webauthn.DecodeBase64(req.Credential.Response.AttestationObject)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attestationObject encoding"})
+		return
+	}
+
+	challenge, err := webauthn.Challenge(clientDataJSON)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid client data"})
+		return
+	}
+
+	ceremony, err := consumeWebAuthnChallenge(challenge, models.WebAuthnPurposeRegistration)
+	if err != nil || ceremony.UserID == nil || *ceremony.UserID != userModel.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired passkey registration, please start again"})
+		return
+	}
+
+	credential, err := relyingParty().VerifyRegistration(challenge, clientDataJSON, attestationObject)
+	if err != nil {
+		log.Printf("Passkey registration for user %s failed: %v", userModel.ID, err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Passkey could not be verified"})
+		return
+	}
+	if webauthn.EncodeBase64(credential.ID) != strings.TrimRight(req.Credential.ID, "=") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Credential ID does not match the attestation"})
+		return
+	}
+
+	var count int64
+	config.DB.Model(&models.WebAuthnCredential{}).Where("credential_id_hash = ?", hashCredentialID(credential.ID)).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "This passkey is already registered"})
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = describeDevice(c.Request.UserAgent())
+	}
+
+	passkey := models.WebAuthnCredential{
+		ID:               uuid.New().String(),
+		UserID:           userModel.ID,
+		Name:             name,
+		CredentialID:     webauthn.EncodeBase64(credential.ID),
+		CredentialIDHash: hashCredentialID(credential.ID),
+		PublicKey:        credential.PublicKey,
+		Algorithm:        credential.Algorithm,
+		SignCount:        credential.SignCount,
+		AAGUID:           hex.EncodeToString(credential.AAGUID),
+		Transports:       textutil.Truncate(strings.Join(req.Credential.Response.Transports, ","), 100),
+		BackupEligible:   credential.BackupEligible,
+		BackupState:      credential.BackupState,
+	}
+	if err := config.DB.Create(&passkey).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save passkey"})
+		return
+	}
+
+	audit.Record(c, audit.Event{
+		Action:     models.AuditActionPasskeyRegister,
+		TargetType: models.AuditTargetPasskey,
+		TargetID:   passkey.ID,
+		Reason:     passkey.Name,
+	})
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Passkey registered successfully",
+		"passkey": convertWebAuthnCredentialToResponse(passkey),
+	})
+}
+
+// BeginPasskeyLogin handles starting a passkey sign-in. With an email the
+// browser is told which of the account's passkeys to offer; without one the
+// authenticator lets the user pick a discoverable passkey. The response is
+// passed to navigator.credentials.get().
+func BeginPasskeyLogin(c *gin.Context) {
+	// The body is optional for sign-in with discoverable passkeys
+	var req models.WebAuthnLoginBeginRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// An unknown email gets the same response as a discoverable sign-in, so
+	// this endpoint does not reveal which addresses are registered
+	var userID *string
+	credentials := []models.WebAuthnCredential{}
+	if req.Email != "" {
+		var user models.User
+		if err := config.DB.Where("email = ?", req.Email).First(&user).Error; err == nil {
+			config.DB.Where("user_id = ?", user.ID).Find(&credentials)
+			if len(credentials) > 0 {
+				userID = &user.ID
+			}
+		}
+	}
+
+	challenge, err := createWebAuthnChallenge(userID, models.WebAuthnPurposeLogin)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start passkey sign-in"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"publicKey": gin.H{
+			"challenge":        challenge,
+			"rpId":             config.WebAuthnRPID(),
+			"timeout":          config.WebAuthnChallengeTTL().Milliseconds(),
+			"allowCredentials": credentialDescriptors(credentials),
+			"userVerification": userVerificationRequirement(),
+		},
+	})
+}
+
+// FinishPasskeyLogin handles signing in with the assertion made by a passkey.
+// A successful response is the same as for a password login.
+func FinishPasskeyLogin(c *gin.Context) {
+	var req models.WebAuthnLoginFinishRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	credentialID, err := webauthn.DecodeBase64(req.Credential.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid credential ID encoding"})
+		return
+	}
+	clientDataJSON, err := webauthn.DecodeBase64(req.Credential.Response.ClientDataJSON)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid clientDataJSON encoding"})
+		return
+	}
+	authenticatorData, err := webauthn.DecodeBase64(req.Credential.Response.AuthenticatorData)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid authenticatorData encoding"})
+		return
+	}
+	signature, err := webauthn.DecodeBase64(req.Credential.Response.Signature)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid signature encoding"})
+		return
+	}
+
+	challenge, err := webauthn.Challenge(clientDataJSON)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid client data"})
+		return
+	}
+
+	// The challenge is used up whether or not the assertion verifies
+	ceremony, err := consumeWebAuthnChallenge(challenge, models.WebAuthnPurposeLogin)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired passkey sign-in, please start again"})
+		return
+	}
+
+	var passkey models.WebAuthnCredential
+	if err := config.DB.Where("credential_id_hash = ?", hashCredentialID(credentialID)).First(&passkey).Error; err != nil {
+		audit.Record(c, audit.Event{
+			Action:  models.AuditActionLoginWebAuthn,
+			Outcome: models.AuditOutcomeFailure,
+			Reason:  "Unknown passkey",
+		})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Passkey is not registered"})
+		return
+	}
+
+	// A sign-in started for one account cannot finish as another, and the
+	// user handle, when sent, must name the passkey's owner
+	if ceremony.UserID != nil && *ceremony.UserID != passkey.UserID {
+		recordAccountFailure(c, models.AuditActionLoginWebAuthn, passkey.UserID, "Passkey belongs to another account")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Passkey could not be verified"})
+		return
+	}
+	if req.Credential.Response.UserHandle != "" {
+		userHandle, err := webauthn.DecodeBase64(req.Credential.Response.UserHandle)
+		if err != nil || subtle.ConstantTimeCompare(userHandle, []byte(passkey.UserID)) != 1 {
+			recordAccountFailure(c, models.AuditActionLoginWebAuthn, passkey.UserID, "User handle mismatch")
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Passkey could not be verified"})
+			return
+		}
+	}
+
+	assertion, err := relyingParty().VerifyAssertion(challenge, passkey.PublicKey, passkey.SignCount, clientDataJSON, authenticatorData, signature)
+	if err != nil {
+		reason := "Invalid assertion"
+		if errors.Is(err, webauthn.ErrSignCount) {
+			reason = "Signature counter did not increase, the passkey may have been cloned"
+		}
+		log.Printf("Passkey sign-in with credential %s failed: %v", passkey.ID, err)
+		recordAccountFailure(c, models.AuditActionLoginWebAuthn, passkey.UserID, reason)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Passkey could not be verified"})
+		return
+	}
+
+	// Only move the counter on from the value the assertion was checked
+	// against, so a concurrent sign-in cannot move it backwards
+	now := time.Now()
+	result := config.DB.Model(&passkey).Where("sign_count = ?", passkey.SignCount).Updates(map[string]interface{}{
+		"sign_count":   assertion.SignCount,
+		"backup_state": assertion.BackupState,
+		"last_used_at": now,
+	})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign in"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		recordAccountFailure(c, models.AuditActionLoginWebAuthn, passkey.UserID, "Signature counter changed during sign-in")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Passkey could not be verified"})
+		return
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, "id = ?", passkey.UserID).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Passkey could not be verified"})
+		return
+	}
+
+	if err := clearLoginThrottle(user.Email); err != nil {
+		log.Printf("Failed to clear login throttle for user %s: %v", user.ID, err)
+	}
+
+	completeLogin(c, user, models.AuditActionLoginWebAuthn)
+}
+
+// ListPasskeys handles listing the authenticated user's passkeys
+func ListPasskeys(c *gin.Context) {
+	// Get user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	userModel := user.(models.User)
+
+	var passkeys []models.WebAuthnCredential
+	if err := config.DB.Where("user_id = ?", userModel.ID).Order("created_at DESC").Find(&passkeys).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch passkeys"})
+		return
+	}
+
+	// Convert to response format
+	passkeysResponse := make([]models.WebAuthnCredentialResponse, 0, len(passkeys))
+	for _, passkey := range passkeys {
+		passkeysResponse = append(passkeysResponse, convertWebAuthnCredentialToResponse(passkey))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"passkeys": passkeysResponse,
+	})
+}
+
+// DeletePasskey handles removing one of the authenticated user's passkeys
+func DeletePasskey(c *gin.Context) {
+	// Get user from context
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	userModel := user.(models.User)
+
+	var passkey models.WebAuthnCredential
+	if err := config.DB.Where("id = ? AND user_id = ?", c.Param("id"), userModel.ID).First(&passkey).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Passkey not found"})
+		return
+	}
+
+	if err := config.DB.Delete(&passkey).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove passkey"})
+		return
+	}
+
+	audit.Record(c, audit.Event{
+		Action:     models.AuditActionPasskeyRemove,
+		TargetType: models.AuditTargetPasskey,
+		TargetID:   passkey.ID,
+		Reason:     passkey.Name,
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Passkey removed successfully",
+	})
+}
+
+// relyingParty returns the WebAuthn settings of this server
+func relyingParty() webauthn.RelyingParty {
+	return webauthn.RelyingParty{
+		ID:                      config.WebAuthnRPID(),
+		Name:                    config.WebAuthnRPName(),
+		Origins:                 config.WebAuthnOrigins(),
+		RequireUserVerification: config.WebAuthnRequireUserVerification(),
+	}
+}
+
+// userVerificationRequirement returns the userVerification option sent to browsers
+func userVerificationRequirement() string {
+	if config.WebAuthnRequireUserVerification() {
+		return "required"
+	}
+	return "preferred"
+}
+
+// createWebAuthnChallenge stores a new ceremony and returns its base64url challenge
+func createWebAuthnChallenge(userID *string, purpose string) (string, error) {
+	challenge, err := generateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	// Drop ceremonies that were never finished
+	config.DB.Where("expires_at < ?", time.Now()).Delete(&models.WebAuthnChallenge{})
+
+	ceremony := models.WebAuthnChallenge{
+		ID:            uuid.New().String(),
+		UserID:        userID,
+		Purpose:       purpose,
+		ChallengeHash: hashToken(challenge),
+		ExpiresAt:     time.Now().Add(config.WebAuthnChallengeTTL()),
+	}
+	if err := config.DB.Create(&ceremony).Error; err != nil {
+		return "", err
+	}
+
+	return challenge, nil
+}
+
+// consumeWebAuthnChallenge marks a pending ceremony as used and returns it
+func consumeWebAuthnChallenge(challenge, purpose string) (*models.WebAuthnChallenge, error) {
+	var ceremony models.WebAuthnChallenge
+	if err := config.DB.Where("challenge_hash = ? AND purpose = ?", hashToken(challenge), purpose).First(&ceremony).Error; err != nil {
+		return nil, errWebAuthnChallengeInvalid
+	}
+
+	if ceremony.UsedAt != nil || time.Now().After(ceremony.ExpiresAt) {
+		return nil, errWebAuthnChallengeInvalid
+	}
+
+	// Guard against the same challenge being used twice concurrently
+	result := config.DB.Model(&models.WebAuthnChallenge{}).
+		Where("id = ? AND used_at IS NULL", ceremony.ID).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errWebAuthnChallengeInvalid
+	}
+
+	return &ceremony, nil
+}
+
+// credentialDescriptors lists passkeys in the form browsers expect in
+// allowCredentials and excludeCredentials
+func credentialDescriptors(credentials []models.WebAuthnCredential) []gin.H {
+	descriptors := make([]gin.H, 0, len(credentials))
+	for _, credential := range credentials {
+		descriptor := gin.H{"type": "public-key", "id": credential.CredentialID}
+		if credential.Transports != "" {
+			descriptor["transports"] = credential.TransportList()
+		}
+		descriptors = append(descriptors, descriptor)
+	}
+	return descriptors
+}
+
+// hashCredentialID returns the lookup hash of a raw credential ID
+func hashCredentialID(credentialID []byte) string {
+	return hashToken(webauthn.EncodeBase64(credentialID))
+}
+
+// convertWebAuthnCredentialToResponse converts a passkey to response format
+func convertWebAuthnCredentialToResponse(credential models.WebAuthnCredential) models.WebAuthnCredentialResponse {
+	return models.WebAuthnCredentialResponse{
+		ID:             credential.ID,
+		Name:           credential.Name,
+		Transports:     credential.TransportList(),
+		BackupEligible: credential.BackupEligible,
+		BackupState:    credential.BackupState,
+		LastUsedAt:     credential.LastUsedAt,
+		CreatedAt:      credential.CreatedAt,
+	}
+}
@@ -0,0 +1,48 @@
+package mail
+
+import (
+	"log"
+
+	"blog-api/config"
+)
+
+// Message is an outgoing email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers email messages
+type Sender interface {
+	Send(msg Message) error
+}
+
+// DefaultSender is the sender used by Send
+var DefaultSender Sender = &LogSender{}
+
+// Setup configures DefaultSender from the MAIL_DRIVER environment variable
+func Setup() {
+	switch driver := config.GetEnv("MAIL_DRIVER", "log"); driver {
+	case "log":
+		DefaultSender = &LogSender{}
+	case "file":
+		DefaultSender = &FileSender{Dir: config.GetEnv("MAIL_FILE_DIR", "mail_outbox")}
+	case "smtp":
+		DefaultSender = &SMTPSender{
+			Host:     config.GetEnv("SMTP_HOST", "localhost"),
+			Port:     config.GetEnv("SMTP_PORT", "587"),
+			Username: config.GetEnv("SMTP_USERNAME", ""),
+			Password: config.GetEnv("SMTP_PASSWORD", ""),
+			From:     config.GetEnv("MAIL_FROM", "no-reply@localhost"),
+		}
+	default:
+		log.Printf("Unknown MAIL_DRIVER %q, falling back to log sender", driver)
+		DefaultSender = &LogSender{}
+	}
+}
+
+// Send delivers a message through DefaultSender
+func Send(msg Message) error {
+	return DefaultSender.Send(msg)
+}
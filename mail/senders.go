@@ -0,0 +1,74 @@
+package mail
+
+import (
+	"fmt"
+	"log"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LogSender writes messages to the application log instead of delivering them.
+// It is intended for local development.
+type LogSender struct{}
+
+// Send logs the message
+func (s *LogSender) Send(msg Message) error {
+	log.Printf("Mail - To: %s, Subject: %s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// FileSender writes each message to its own file in Dir so that development
+// setups and test scripts can read the links that were sent.
+type FileSender struct {
+	Dir string
+}
+
+// Send writes the message to a new .eml file
+func (s *FileSender) Send(msg Message) error {
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s_%s.eml", time.Now().Format("20060102T150405"), uuid.New().String())
+	return os.WriteFile(filepath.Join(s.Dir, name), []byte(formatMessage("", msg)), 0o644)
+}
+
+// SMTPSender delivers messages through an SMTP server
+type SMTPSender struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// Send delivers the message over SMTP
+func (s *SMTPSender) Send(msg Message) error {
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, s.Password, s.Host)
+	}
+
+	addr := s.Host + ":" + s.Port
+	return smtp.SendMail(addr, auth, s.From, []string{msg.To}, []byte(formatMessage(s.From, msg)))
+}
+
+// formatMessage renders a message as a plain-text RFC 5322 document
+func formatMessage(from string, msg Message) string {
+	var b strings.Builder
+	if from != "" {
+		b.WriteString("From: " + from + "\r\n")
+	}
+	b.WriteString("To: " + msg.To + "\r\n")
+	b.WriteString("Subject: " + msg.Subject + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(msg.Body)
+	return b.String()
+}
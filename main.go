@@ -4,7 +4,12 @@ import (
 	"log"
 	"os"
 
+	"blog-api/auth"
 	"blog-api/config"
+	"blog-api/handlers"
+	"blog-api/mail"
+	"blog-api/oauth"
+	"blog-api/password"
 	"blog-api/routes"
 
 	"github.com/joho/godotenv"
@@ -27,6 +32,27 @@ func main() {
 	// Connect to database
 	config.ConnectDatabase()
 
+	// Give posts from before slugs were introduced a slug
+	handlers.BackfillPostSlugs()
+
+	// Load or generate the access token signing keys
+	auth.Setup()
+
+	// Configure outgoing mail
+	mail.Setup()
+
+	// Load the password policy and breached password list
+	password.Setup()
+
+	// Enable sign-in with external identity providers
+	oauth.Setup()
+
+	// Delete accounts whose deletion grace period is over
+	handlers.StartAccountDeletionWorker()
+
+	// Publish scheduled posts when they are due
+	handlers.StartPostScheduler()
+
 	// Setup routes
 	router := routes.SetupRoutes(logger)
 
@@ -1,82 +1,111 @@
 package middleware
 
 import (
+	"fmt"
 	"net/http"
-	"os"
 	"strings"
 
+	"blog-api/audit"
+	"blog-api/auth"
 	"blog-api/config"
 	"blog-api/models"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 )
 
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		// Authenticate with an API key if one was sent
+		if rawKey := apiKeyFromRequest(c); rawKey != "" {
+			user, apiKey, err := authenticateAPIKey(rawKey)
+			if err != nil {
+				denyAccess(c, "", "Invalid API key")
+				return
+			}
+
+			if rejectInactiveUser(c, *user) {
+				return
+			}
+
+			c.Set("user", *user)
+			c.Set("api_key", *apiKey)
+			c.Next()
+			return
+		}
+
 		// Get token from Authorization header
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
-			c.Abort()
+			denyAccess(c, "", "Authorization header required")
 			return
 		}
 
 		// Check if token starts with "Bearer "
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 		if tokenString == authHeader {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization header format"})
-			c.Abort()
+			denyAccess(c, "", "Invalid authorization header format")
 			return
 		}
 
-		// Parse and validate token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// Validate signing method
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return []byte(os.Getenv("JWT_SECRET")), nil
-		})
-
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-			c.Abort()
+		// Parse and validate token signature and claims
+		claims, err := auth.ParseAccessToken(tokenString)
+		if err != nil {
+			denyAccess(c, "", "Invalid token")
 			return
 		}
 
-		// Extract claims
-		claims, ok := token.Claims.(jwt.MapClaims)
+		// Get user ID from claims
+		userID, ok := claims["user_id"].(string)
 		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
-			c.Abort()
+			denyAccess(c, "", "Invalid user ID in token")
 			return
 		}
 
-		// Get user ID from claims
-		userID, ok := claims["user_id"].(string)
-		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID in token"})
-			c.Abort()
+		// Reject tokens whose session has been revoked
+		session, err := authenticateSession(claims, userID, c.ClientIP())
+		if err != nil {
+			denyAccess(c, userID, "Session revoked")
 			return
 		}
 
 		// Get user from database
 		var user models.User
 		if err := config.DB.First(&user, "id = ?", userID).Error; err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
-			c.Abort()
+			denyAccess(c, userID, "User not found")
+			return
+		}
+
+		// Reject tokens issued before the password was last changed
+		if issuedBeforePasswordChange(claims, user) {
+			denyAccess(c, userID, "Password was changed, please log in again")
 			return
 		}
 
-		// Set user in context
+		// Reject suspended accounts and accounts that must reset their password
+		if rejectInactiveUser(c, user) {
+			return
+		}
+
+		// Set user and session in context
 		c.Set("user", user)
+		c.Set("session_id", session.ID)
 		c.Next()
 	}
 }
 
 func OptionalAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		// Authenticate with an API key if one was sent
+		if rawKey := apiKeyFromRequest(c); rawKey != "" {
+			user, apiKey, err := authenticateAPIKey(rawKey)
+			if err == nil && !user.IsSuspended() && !user.PasswordResetRequired {
+				c.Set("user", *user)
+				c.Set("api_key", *apiKey)
+			}
+			c.Next()
+			return
+		}
+
 		// Get token from Authorization header
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -91,30 +120,23 @@ func OptionalAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// Parse and validate token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// Validate signing method
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return []byte(os.Getenv("JWT_SECRET")), nil
-		})
-
-		if err != nil || !token.Valid {
+		// Parse and validate token signature and claims
+		claims, err := auth.ParseAccessToken(tokenString)
+		if err != nil {
 			c.Next()
 			return
 		}
 
-		// Extract claims
-		claims, ok := token.Claims.(jwt.MapClaims)
+		// Get user ID from claims
+		userID, ok := claims["user_id"].(string)
 		if !ok {
 			c.Next()
 			return
 		}
 
-		// Get user ID from claims
-		userID, ok := claims["user_id"].(string)
-		if !ok {
+		// Ignore tokens whose session has been revoked
+		session, err := authenticateSession(claims, userID, c.ClientIP())
+		if err != nil {
 			c.Next()
 			return
 		}
@@ -126,8 +148,56 @@ func OptionalAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// Set user in context
+		// Treat suspended accounts and outdated tokens as anonymous
+		if user.IsSuspended() || user.PasswordResetRequired || issuedBeforePasswordChange(claims, user) {
+			c.Next()
+			return
+		}
+
+		// Set user and session in context
 		c.Set("user", user)
+		c.Set("session_id", session.ID)
 		c.Next()
 	}
 }
+
+// denyAccess aborts the request with 401 and records the denial in the audit log
+func denyAccess(c *gin.Context, userID, message string) {
+	recordDenial(c, userID, message)
+	c.JSON(http.StatusUnauthorized, gin.H{"error": message})
+	c.Abort()
+}
+
+// recordDenial records in the audit log that a request was turned away
+func recordDenial(c *gin.Context, userID, reason string) {
+	audit.Record(c, audit.Event{
+		Action:  models.AuditActionAccessDenied,
+		ActorID: userID,
+		Outcome: models.AuditOutcomeDenied,
+		Reason:  fmt.Sprintf("%s (%s %s)", reason, c.Request.Method, c.Request.URL.Path),
+	})
+}
+
+// rejectInactiveUser aborts the request with 403 when the account is suspended
+// or must reset its password. It reports whether the request was aborted.
+func rejectInactiveUser(c *gin.Context, user models.User) bool {
+	if user.IsSuspended() {
+		recordDenial(c, user.ID, "Account suspended")
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":           "Account suspended",
+			"reason":          user.SuspensionReason,
+			"suspended_until": user.SuspendedUntil,
+		})
+		c.Abort()
+		return true
+	}
+
+	if user.PasswordResetRequired {
+		recordDenial(c, user.ID, "Password reset required")
+		c.JSON(http.StatusForbidden, gin.H{"error": "Password reset required"})
+		c.Abort()
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http"
+
+	"blog-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission rejects users whose role does not grant every listed
+// permission. It must run after AuthMiddleware.
+func RequirePermission(permissions ...models.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, exists := c.Get("user")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+			c.Abort()
+			return
+		}
+
+		userModel := user.(models.User)
+		for _, permission := range permissions {
+			if !userModel.HasPermission(permission) {
+				c.JSON(http.StatusForbidden, gin.H{
+					"error":      "You do not have permission to perform this action",
+					"permission": permission,
+				})
+				c.Abort()
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
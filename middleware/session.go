@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"errors"
+	"time"
+
+	"blog-api/config"
+	"blog-api/models"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// lastSeenInterval limits how often a session's last-seen time is written
+const lastSeenInterval = time.Minute
+
+var errSessionRevoked = errors.New("session revoked")
+
+// authenticateSession returns the active session named by the token's "sid"
+// claim and records that it was just used from the given IP address
+func authenticateSession(claims jwt.MapClaims, userID, ipAddress string) (*models.Session, error) {
+	sessionID, ok := claims["sid"].(string)
+	if !ok || sessionID == "" {
+		return nil, errSessionRevoked
+	}
+
+	var session models.Session
+	if err := config.DB.First(&session, "id = ? AND user_id = ?", sessionID, userID).Error; err != nil {
+		return nil, errSessionRevoked
+	}
+	if session.RevokedAt != nil {
+		return nil, errSessionRevoked
+	}
+
+	if time.Since(session.LastSeenAt) > lastSeenInterval || session.IPAddress != ipAddress {
+		now := time.Now()
+		config.DB.Model(&session).Updates(map[string]interface{}{
+			"last_seen_at": now,
+			"ip_address":   ipAddress,
+		})
+		session.LastSeenAt = now
+		session.IPAddress = ipAddress
+	}
+
+	return &session, nil
+}
+
+// issuedBeforePasswordChange reports whether the token's iat claim predates
+// the user's last password change
+func issuedBeforePasswordChange(claims jwt.MapClaims, user models.User) bool {
+	if user.PasswordChangedAt == nil {
+		return false
+	}
+
+	issuedAt, err := claims.GetIssuedAt()
+	if err != nil || issuedAt == nil {
+		return true
+	}
+
+	// iat has one-second precision, so compare whole seconds
+	return issuedAt.Unix() < user.PasswordChangedAt.Unix()
+}
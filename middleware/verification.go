@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http"
+
+	"blog-api/config"
+	"blog-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireVerifiedEmail blocks users who have not verified their email address
+// when REQUIRE_EMAIL_VERIFICATION is enabled. It must run after AuthMiddleware.
+func RequireVerifiedEmail() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !config.RequireEmailVerification() {
+			c.Next()
+			return
+		}
+
+		user, exists := c.Get("user")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+			c.Abort()
+			return
+		}
+
+		if user.(models.User).EmailVerifiedAt == nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Please verify your email address before posting"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"time"
+)
+
+// APIKeyPrefix starts every API key so that keys can be told apart from JWTs
+const APIKeyPrefix = "blog_"
+
+// Scopes that can be granted to an API key
+const (
+	ScopePostsRead     = "posts:read"
+	ScopePostsWrite    = "posts:write"
+	ScopeCommentsRead  = "comments:read"
+	ScopeCommentsWrite = "comments:write"
+	ScopeLikesRead     = "likes:read"
+	ScopeLikesWrite    = "likes:write"
+	ScopeProfileRead   = "profile:read"
+	ScopeProfileWrite  = "profile:write"
+)
+
+// APIKeyScopes lists every valid scope
+var APIKeyScopes = []string{
+	ScopePostsRead,
+	ScopePostsWrite,
+	ScopeCommentsRead,
+	ScopeCommentsWrite,
+	ScopeLikesRead,
+	ScopeLikesWrite,
+	ScopeProfileRead,
+	ScopeProfileWrite,
+}
+
+// APIKey is a long-lived personal credential for scripts and integrations.
+// A key without scopes may act on every scoped route on behalf of its owner.
+type APIKey struct {
+	ID         string     `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	UserID     string     `json:"user_id" gorm:"type:varchar(36);not null;index"`
+	Name       string     `json:"name" gorm:"type:varchar(100);not null"`
+	Prefix     string     `json:"prefix" gorm:"type:varchar(16);not null"`
+	KeyHash    string     `json:"-" gorm:"uniqueIndex;type:varchar(64);not null"`
+	Scopes     []string   `json:"scopes" gorm:"serializer:json;type:json"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+
+	// Relationships
+	User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+type APIKeyCreateRequest struct {
+	Name      string     `json:"name" binding:"required,min=1,max=100"`
+	Scopes    []string   `json:"scopes" binding:"dive,oneof=posts:read posts:write comments:read comments:write likes:read likes:write profile:read profile:write"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type APIKeyResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// IsActive reports whether the key is neither revoked nor expired
+func (k APIKey) IsActive() bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || time.Now().Before(*k.ExpiresAt)
+}
+
+// HasScope reports whether the key grants the scope
+func (k APIKey) HasScope(scope string) bool {
+	if len(k.Scopes) == 0 {
+		return true
+	}
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
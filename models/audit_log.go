@@ -0,0 +1,94 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Audit log actions
+const (
+	AuditActionRegister           = "auth.register"
+	AuditActionLogin              = "auth.login"
+	AuditActionLoginTwoFactor     = "auth.login.2fa"
+	AuditActionLoginMagicLink     = "auth.login.magic_link"
+	AuditActionLoginOAuth         = "auth.login.oauth"
+	AuditActionLoginWebAuthn      = "auth.login.webauthn"
+	AuditActionLogout             = "auth.logout"
+	AuditActionRefresh            = "auth.refresh"
+	AuditActionAccessDenied       = "auth.denied"
+	AuditActionAccountUnlock      = "auth.unlock"
+	AuditActionPasswordChange     = "account.password.change"
+	AuditActionPasswordReset      = "account.password.reset"
+	AuditActionEmailVerify        = "account.email.verify"
+	AuditActionProfileUpdate      = "account.profile.update"
+	AuditActionTwoFactorEnable    = "account.2fa.enable"
+	AuditActionTwoFactorDisable   = "account.2fa.disable"
+	AuditActionRecoveryCodes      = "account.2fa.recovery_codes"
+	AuditActionAPIKeyCreate       = "account.api_key.create"
+	AuditActionAPIKeyRevoke       = "account.api_key.revoke"
+	AuditActionSessionRevoke      = "account.session.revoke"
+	AuditActionIdentityLink       = "account.identity.link"
+	AuditActionIdentityUnlink     = "account.identity.unlink"
+	AuditActionPasskeyRegister    = "account.passkey.register"
+	AuditActionPasskeyRemove      = "account.passkey.remove"
+	AuditActionExport             = "account.export"
+	AuditActionDeletionRequest    = "account.deletion.request"
+	AuditActionDeletionCancel     = "account.deletion.cancel"
+	AuditActionDeletionComplete   = "account.deletion.complete"
+	AuditActionAdminRoleChange    = "admin.user.role"
+	AuditActionAdminSuspend       = "admin.user.suspend"
+	AuditActionAdminUnsuspend     = "admin.user.unsuspend"
+	AuditActionAdminPasswordReset = "admin.user.force_password_reset"
+	AuditActionAdminUnlock        = "admin.user.unlock"
+	AuditActionAdminDelete        = "admin.user.delete"
+	AuditActionAdminInviteCreate  = "admin.invite.create"
+	AuditActionAdminInviteRevoke  = "admin.invite.revoke"
+)
+
+// Audit log outcomes
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+	AuditOutcomeDenied  = "denied"
+)
+
+// Audit log target types
+const (
+	AuditTargetUser     = "user"
+	AuditTargetSession  = "session"
+	AuditTargetAPIKey   = "api_key"
+	AuditTargetInvite   = "invite"
+	AuditTargetEmail    = "email"
+	AuditTargetIdentity = "oauth_identity"
+	AuditTargetPasskey  = "passkey"
+)
+
+// ErrAuditLogAppendOnly is returned when an audit log entry is changed or deleted
+var ErrAuditLogAppendOnly = errors.New("audit log entries cannot be changed or deleted")
+
+// AuditLog is an append-only record of an authentication or account event.
+// Entries outlive the users they mention, so there is no foreign key.
+type AuditLog struct {
+	ID         string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	ActorID    *string   `json:"actor_id" gorm:"type:varchar(36);index"`
+	Action     string    `json:"action" gorm:"type:varchar(64);not null;index"`
+	TargetType string    `json:"target_type,omitempty" gorm:"type:varchar(32)"`
+	TargetID   string    `json:"target_id,omitempty" gorm:"type:varchar(255);index"`
+	IPAddress  string    `json:"ip_address" gorm:"type:varchar(45)"`
+	UserAgent  string    `json:"user_agent" gorm:"type:varchar(500)"`
+	Outcome    string    `json:"outcome" gorm:"type:varchar(16);not null;index"`
+	Reason     string    `json:"reason,omitempty" gorm:"type:varchar(255)"`
+	CreatedAt  time.Time `json:"created_at" gorm:"index"`
+}
+
+// BeforeUpdate keeps audit log entries from being changed
+func (AuditLog) BeforeUpdate(tx *gorm.DB) error {
+	return ErrAuditLogAppendOnly
+}
+
+// BeforeDelete keeps audit log entries from being deleted
+func (AuditLog) BeforeDelete(tx *gorm.DB) error {
+	return ErrAuditLogAppendOnly
+}
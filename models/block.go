@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+)
+
+// Block records that one user has blocked another. Blocked users cannot reply
+// to, like, or follow the user who blocked them.
+type Block struct {
+	ID        string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	BlockerID string    `json:"blocker_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_blocks_blocker_blocked"`
+	BlockedID string    `json:"blocked_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_blocks_blocker_blocked;index"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Relationships
+	Blocker User `json:"blocker,omitempty" gorm:"foreignKey:BlockerID"`
+	Blocked User `json:"blocked,omitempty" gorm:"foreignKey:BlockedID"`
+}
+
+// Mute records that one user has muted another. Content by muted users is
+// hidden from the muting user's feed and comment views.
+type Mute struct {
+	ID        string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	MuterID   string    `json:"muter_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_mutes_muter_muted"`
+	MutedID   string    `json:"muted_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_mutes_muter_muted;index"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Relationships
+	Muter User `json:"muter,omitempty" gorm:"foreignKey:MuterID"`
+	Muted User `json:"muted,omitempty" gorm:"foreignKey:MutedID"`
+}
+
+// BlockResponse is an entry in the authenticated user's block list
+type BlockResponse struct {
+	User      UserResponse `json:"user"`
+	BlockedAt time.Time    `json:"blocked_at"`
+}
+
+// MuteResponse is an entry in the authenticated user's mute list
+type MuteResponse struct {
+	User    UserResponse `json:"user"`
+	MutedAt time.Time    `json:"muted_at"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+)
+
+// AccountExport is everything a user has contributed, as included in their data export
+type AccountExport struct {
+	ExportedAt time.Time       `json:"exported_at"`
+	Profile    ProfileResponse `json:"profile"`
+	Posts      []ExportPost    `json:"posts"`
+	Comments   []ExportComment `json:"comments"`
+	Likes      []ExportLike    `json:"likes"`
+}
+
+type ExportPost struct {
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	Content     string     `json:"content"`
+	Tags        []string   `json:"tags"`
+	Status      string     `json:"status"`
+	PublishedAt *time.Time `json:"published_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+type ExportComment struct {
+	ID              string    `json:"id"`
+	PostID          string    `json:"post_id"`
+	PostTitle       string    `json:"post_title"`
+	ParentCommentID *string   `json:"parent_comment_id"`
+	Content         string    `json:"content"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+type ExportLike struct {
+	PostID    string    `json:"post_id"`
+	PostTitle string    `json:"post_title"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+// Follow records that one user follows another
+type Follow struct {
+	ID          string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	FollowerID  string    `json:"follower_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_follows_follower_following"`
+	FollowingID string    `json:"following_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_follows_follower_following;index"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	// Relationships
+	Follower  User `json:"follower,omitempty" gorm:"foreignKey:FollowerID"`
+	Following User `json:"following,omitempty" gorm:"foreignKey:FollowingID"`
+}
+
+// FollowResponse is an entry in a follower or following list
+type FollowResponse struct {
+	User       UserResponse `json:"user"`
+	FollowedAt time.Time    `json:"followed_at"`
+}
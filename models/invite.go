@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+)
+
+// Invite is an admin-issued code that lets people register while registration
+// is invite-only. Accounts created with it get its role.
+type Invite struct {
+	ID          string     `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	CodeHash    string     `json:"-" gorm:"uniqueIndex;type:varchar(64);not null"`
+	Prefix      string     `json:"prefix" gorm:"type:varchar(16);not null"`
+	Role        string     `json:"role" gorm:"type:varchar(20);not null"`
+	Note        string     `json:"note" gorm:"type:varchar(255)"`
+	MaxUses     int        `json:"max_uses" gorm:"not null;default:1"`
+	Uses        int        `json:"uses" gorm:"not null;default:0"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+	CreatedByID string     `json:"created_by_id" gorm:"type:varchar(36);not null;index"`
+	CreatedAt   time.Time  `json:"created_at"`
+
+	// Relationships
+	CreatedBy User `json:"created_by,omitempty" gorm:"foreignKey:CreatedByID"`
+}
+
+type InviteCreateRequest struct {
+	Role      string     `json:"role" binding:"omitempty,oneof=user author editor moderator admin"`
+	MaxUses   int        `json:"max_uses" binding:"omitempty,min=1,max=10000"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	Note      string     `json:"note" binding:"max=255"`
+}
+
+type InviteResponse struct {
+	ID          string     `json:"id"`
+	Prefix      string     `json:"prefix"`
+	Role        string     `json:"role"`
+	Note        string     `json:"note"`
+	MaxUses     int        `json:"max_uses"`
+	Uses        int        `json:"uses"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+	Active      bool       `json:"active"`
+	CreatedByID string     `json:"created_by_id"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// IsActive reports whether the invite can still be redeemed
+func (i Invite) IsActive() bool {
+	if i.RevokedAt != nil || i.Uses >= i.MaxUses {
+		return false
+	}
+	return i.ExpiresAt == nil || time.Now().Before(*i.ExpiresAt)
+}
@@ -16,9 +16,9 @@ type Like struct {
 }
 
 type LikeResponse struct {
-	ID        string    `json:"id"`
-	PostID    string    `json:"post_id"`
-	UserID    string    `json:"user_id"`
+	ID        string       `json:"id"`
+	PostID    string       `json:"post_id"`
+	UserID    string       `json:"user_id"`
 	User      UserResponse `json:"user,omitempty"`
-	CreatedAt time.Time `json:"created_at"`
+	CreatedAt time.Time    `json:"created_at"`
 }
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+)
+
+// LoginThrottle tracks consecutive failed logins for one subject, either an
+// account (keyed by email address) or a client IP address
+type LoginThrottle struct {
+	// ID identifies the throttled subject, e.g. "account:jane@example.com" or "ip:203.0.113.7"
+	ID            string     `json:"id" gorm:"primaryKey;type:varchar(191)"`
+	Failures      int        `json:"failures" gorm:"not null;default:0"`
+	LastFailureAt time.Time  `json:"last_failure_at"`
+	LockedUntil   *time.Time `json:"locked_until"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+type UnlockAccountRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// IsLocked reports whether the subject is locked out at the given time
+func (t LoginThrottle) IsLocked(now time.Time) bool {
+	return t.LockedUntil != nil && now.Before(*t.LockedUntil)
+}
+
+// RetryAfter returns how long the subject must wait before the next attempt.
+// Each consecutive failure doubles the delay, starting at baseDelay and capped
+// at maxDelay; a lockout overrides the backoff.
+func (t LoginThrottle) RetryAfter(now time.Time, baseDelay, maxDelay time.Duration) time.Duration {
+	if t.IsLocked(now) {
+		return t.LockedUntil.Sub(now)
+	}
+	if t.Failures == 0 {
+		return 0
+	}
+
+	delay := baseDelay
+	for i := 1; i < t.Failures && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+
+	if wait := t.LastFailureAt.Add(delay).Sub(now); wait > 0 {
+		return wait
+	}
+	return 0
+}
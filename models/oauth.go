@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+)
+
+// OAuthIdentity links a user to their account at an external identity provider
+type OAuthIdentity struct {
+	ID          string     `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	UserID      string     `json:"user_id" gorm:"type:varchar(36);not null;index"`
+	Provider    string     `json:"provider" gorm:"type:varchar(50);not null;uniqueIndex:idx_oauth_identities_provider_subject"`
+	Subject     string     `json:"subject" gorm:"type:varchar(255);not null;uniqueIndex:idx_oauth_identities_provider_subject"`
+	Email       string     `json:"email" gorm:"type:varchar(100)"`
+	Username    string     `json:"username" gorm:"type:varchar(100)"`
+	LastLoginAt *time.Time `json:"last_login_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+
+	// Relationships
+	User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+// OAuthState is a pending authorization request. It holds the PKCE code
+// verifier and ID token nonce until the provider redirects back. UserID is set
+// when a signed-in user is linking another identity, and LinkBindingHash then
+// ties the request to the browser that started it; InviteCode is used if the
+// sign-in creates an account.
+type OAuthState struct {
+	ID              string     `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	Provider        string     `json:"provider" gorm:"type:varchar(50);not null"`
+	StateHash       string     `json:"-" gorm:"type:varchar(64);uniqueIndex;not null"`
+	CodeVerifier    string     `json:"-" gorm:"type:varchar(128);not null"`
+	Nonce           string     `json:"-" gorm:"type:varchar(64);not null"`
+	RedirectURI     string     `json:"redirect_uri" gorm:"type:varchar(500);not null"`
+	UserID          *string    `json:"user_id" gorm:"type:varchar(36);index"`
+	LinkBindingHash string     `json:"-" gorm:"type:varchar(64)"`
+	InviteCode      string     `json:"-" gorm:"type:varchar(32)"`
+	ExpiresAt       time.Time  `json:"expires_at"`
+	UsedAt          *time.Time `json:"used_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+type OAuthCallbackRequest struct {
+	Code  string `json:"code" form:"code" binding:"required"`
+	State string `json:"state" form:"state" binding:"required"`
+}
+
+type OAuthIdentityResponse struct {
+	ID          string     `json:"id"`
+	Provider    string     `json:"provider"`
+	Email       string     `json:"email"`
+	Username    string     `json:"username"`
+	LastLoginAt *time.Time `json:"last_login_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
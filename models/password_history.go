@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+)
+
+// PasswordHistory keeps the hashes of a user's previous passwords so they
+// cannot be chosen again
+type PasswordHistory struct {
+	ID           string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	UserID       string    `json:"user_id" gorm:"type:varchar(36);not null;index"`
+	PasswordHash string    `json:"-" gorm:"type:varchar(255);not null"`
+	CreatedAt    time.Time `json:"created_at"`
+
+	// Relationships
+	User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
@@ -6,16 +6,41 @@ import (
 	"gorm.io/gorm"
 )
 
+// Post statuses
+const (
+	PostStatusDraft     = "draft"
+	PostStatusInReview  = "in_review"
+	PostStatusScheduled = "scheduled"
+	PostStatusPublished = "published"
+	PostStatusArchived  = "archived"
+)
+
+// postTransitions lists the statuses a post may move to from each status
+var postTransitions = map[string][]string{
+	PostStatusDraft:     {PostStatusInReview, PostStatusScheduled, PostStatusPublished},
+	PostStatusInReview:  {PostStatusDraft, PostStatusScheduled, PostStatusPublished},
+	PostStatusScheduled: {PostStatusDraft, PostStatusPublished},
+	PostStatusPublished: {PostStatusArchived, PostStatusDraft},
+	PostStatusArchived:  {PostStatusDraft, PostStatusScheduled, PostStatusPublished},
+}
+
 type Post struct {
-	ID        string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
-	Title     string    `json:"title" gorm:"type:varchar(255);not null"`
-	Content   string    `json:"content" gorm:"type:text;not null"`
-	Tags      []string  `json:"tags" gorm:"type:json"`
-	AuthorID  string    `json:"author_id" gorm:"type:varchar(36);not null"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        string         `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	Slug      string         `json:"slug" gorm:"type:varchar(255);index"`
+	Title     string         `json:"title" gorm:"type:varchar(255);not null"`
+	Content   string         `json:"content" gorm:"type:text;not null"`
+	Tags      []string       `json:"tags" gorm:"type:json"`
+	AuthorID  string         `json:"author_id" gorm:"type:varchar(36);not null"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 
+	// Publishing workflow. Status defaults to published so posts written before
+	// the workflow stay visible. PublishAt is when a scheduled post goes live.
+	Status      string     `json:"status" gorm:"type:varchar(20);not null;default:published;index"`
+	PublishedAt *time.Time `json:"published_at" gorm:"index"`
+	PublishAt   *time.Time `json:"publish_at" gorm:"index"`
+
 	// Relationships
 	Author   User      `json:"author,omitempty" gorm:"foreignKey:AuthorID"`
 	Comments []Comment `json:"comments,omitempty" gorm:"foreignKey:PostID"`
@@ -26,24 +51,69 @@ type PostCreateRequest struct {
 	Title   string   `json:"title" binding:"required,min=1,max=255"`
 	Content string   `json:"content" binding:"required,min=1"`
 	Tags    []string `json:"tags"`
+	// Slug is generated from the title when omitted
+	Slug string `json:"slug" binding:"omitempty,max=100"`
+	// Status is the initial status, draft when omitted
+	Status string `json:"status" binding:"omitempty,oneof=draft in_review scheduled published"`
+	// PublishAt is required when Status is scheduled
+	PublishAt *time.Time `json:"publish_at"`
 }
 
 type PostUpdateRequest struct {
 	Title   string   `json:"title" binding:"omitempty,min=1,max=255"`
 	Content string   `json:"content" binding:"omitempty,min=1"`
 	Tags    []string `json:"tags"`
+	// Slug replaces the current slug. When omitted, a new title gets a new slug.
+	Slug string `json:"slug" binding:"omitempty,max=100"`
+}
+
+// PostStatusRequest moves a post to another status
+type PostStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=draft in_review scheduled published archived"`
+	// PublishAt is required when Status is scheduled
+	PublishAt *time.Time `json:"publish_at"`
+}
+
+// PostScheduleRequest moves a scheduled post to another publish time
+type PostScheduleRequest struct {
+	PublishAt time.Time `json:"publish_at" binding:"required"`
 }
 
 type PostResponse struct {
-	ID        string         `json:"id"`
-	Title     string         `json:"title"`
-	Content   string         `json:"content"`
-	Tags      []string       `json:"tags"`
-	AuthorID  string         `json:"author_id"`
-	Author    UserResponse   `json:"author,omitempty"`
-	Comments  []CommentResponse `json:"comments,omitempty"`
-	Likes     []LikeResponse `json:"likes,omitempty"`
-	LikesCount int           `json:"likes_count"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
+	ID          string            `json:"id"`
+	Slug        string            `json:"slug"`
+	Title       string            `json:"title"`
+	Content     string            `json:"content"`
+	Tags        []string          `json:"tags"`
+	AuthorID    string            `json:"author_id"`
+	Author      UserResponse      `json:"author,omitempty"`
+	Status      string            `json:"status"`
+	PublishedAt *time.Time        `json:"published_at"`
+	PublishAt   *time.Time        `json:"publish_at,omitempty"`
+	Comments    []CommentResponse `json:"comments,omitempty"`
+	Likes       []LikeResponse    `json:"likes,omitempty"`
+	LikesCount  int               `json:"likes_count"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+// CanTransitionTo reports whether the post may move from its current status to status
+func (p Post) CanTransitionTo(status string) bool {
+	for _, allowed := range postTransitions[p.Status] {
+		if allowed == status {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidPostStatus reports whether status is a known post status
+func IsValidPostStatus(status string) bool {
+	_, ok := postTransitions[status]
+	return ok
+}
+
+// IsPublished reports whether the post is visible to everyone
+func (p Post) IsPublished() bool {
+	return p.Status == PostStatusPublished
 }
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"blog-api/diff"
+
+	"gorm.io/gorm"
+)
+
+// ErrPostRevisionImmutable is returned when a stored post revision is changed
+var ErrPostRevisionImmutable = errors.New("post revisions cannot be changed")
+
+// PostRevision is an immutable snapshot of a post's title, content, and tags,
+// stored every time the post is created or edited. Revisions outlive the
+// users who made them, so there is no foreign key to the editor.
+type PostRevision struct {
+	ID       string   `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	PostID   string   `json:"post_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_post_revisions_post_number"`
+	Number   int      `json:"number" gorm:"not null;uniqueIndex:idx_post_revisions_post_number"`
+	EditorID string   `json:"editor_id" gorm:"type:varchar(36);not null;index"`
+	Title    string   `json:"title" gorm:"type:varchar(255);not null"`
+	Content  string   `json:"content" gorm:"type:text;not null"`
+	Tags     []string `json:"tags" gorm:"serializer:json;type:json"`
+	// RestoredFrom is the number of the revision this one restored, if any
+	RestoredFrom *int      `json:"restored_from"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// BeforeUpdate keeps post revisions from being changed
+func (PostRevision) BeforeUpdate(tx *gorm.DB) error {
+	return ErrPostRevisionImmutable
+}
+
+// PostRevisionSummary describes a revision in a revision list
+type PostRevisionSummary struct {
+	Number       int           `json:"number"`
+	Title        string        `json:"title"`
+	Editor       *UserResponse `json:"editor"`
+	RestoredFrom *int          `json:"restored_from,omitempty"`
+	CreatedAt    time.Time     `json:"created_at"`
+}
+
+// PostRevisionResponse is a revision with its full content
+type PostRevisionResponse struct {
+	Number       int           `json:"number"`
+	Title        string        `json:"title"`
+	Content      string        `json:"content"`
+	Tags         []string      `json:"tags"`
+	Editor       *UserResponse `json:"editor"`
+	RestoredFrom *int          `json:"restored_from,omitempty"`
+	CreatedAt    time.Time     `json:"created_at"`
+}
+
+// PostRevisionDiffResponse compares two revisions of a post
+type PostRevisionDiffResponse struct {
+	From         int         `json:"from"`
+	To           int         `json:"to"`
+	TitleChanged bool        `json:"title_changed"`
+	OldTitle     string      `json:"old_title"`
+	NewTitle     string      `json:"new_title"`
+	OldTags      []string    `json:"old_tags"`
+	NewTags      []string    `json:"new_tags"`
+	Lines        []diff.Line `json:"lines"`
+	LinesAdded   int         `json:"lines_added"`
+	LinesRemoved int         `json:"lines_removed"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import (
+	"time"
+)
+
+// PostSlug reserves a slug for a post. Every slug a post has had is kept so
+// that old links keep resolving after its title changes.
+type PostSlug struct {
+	ID        string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	PostID    string    `json:"post_id" gorm:"type:varchar(36);not null;index"`
+	Slug      string    `json:"slug" gorm:"type:varchar(255);not null;uniqueIndex"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+)
+
+// RefreshToken is a single-use credential exchanged for a new access token.
+// Tokens issued from the same login share a FamilyID, which is the ID of the
+// login's Session, so that the whole chain can be revoked when a rotated token
+// is presented again.
+type RefreshToken struct {
+	ID         string     `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	UserID     string     `json:"user_id" gorm:"type:varchar(36);not null;index"`
+	FamilyID   string     `json:"family_id" gorm:"type:varchar(36);not null;index"`
+	TokenHash  string     `json:"-" gorm:"uniqueIndex;type:varchar(64);not null"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	ReplacedBy *string    `json:"replaced_by" gorm:"type:varchar(36);null"`
+	CreatedAt  time.Time  `json:"created_at"`
+
+	// Relationships
+	User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
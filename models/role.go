@@ -0,0 +1,83 @@
+package models
+
+// Role names assignable to users
+const (
+	RoleUser      = "user"
+	RoleAuthor    = "author"
+	RoleEditor    = "editor"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// Permission is a named capability granted through a role
+type Permission string
+
+const (
+	PermissionCreatePost    Permission = "posts:create"
+	PermissionEditAnyPost   Permission = "posts:edit_any"
+	PermissionDeleteAnyPost Permission = "posts:delete_any"
+	PermissionPublishPost   Permission = "posts:publish"
+
+	PermissionCreateComment    Permission = "comments:create"
+	PermissionEditAnyComment   Permission = "comments:edit_any"
+	PermissionDeleteAnyComment Permission = "comments:delete_any"
+
+	PermissionManageUsers Permission = "users:manage"
+)
+
+// rolePermissions lists the permissions each role grants
+var rolePermissions = map[string][]Permission{
+	RoleUser: {
+		PermissionCreateComment,
+	},
+	RoleAuthor: {
+		PermissionCreateComment,
+		PermissionCreatePost,
+		PermissionPublishPost,
+	},
+	RoleEditor: {
+		PermissionCreateComment,
+		PermissionCreatePost,
+		PermissionEditAnyPost,
+		PermissionDeleteAnyPost,
+		PermissionPublishPost,
+	},
+	RoleModerator: {
+		PermissionCreateComment,
+		PermissionCreatePost,
+		PermissionPublishPost,
+		PermissionEditAnyComment,
+		PermissionDeleteAnyComment,
+	},
+	RoleAdmin: {
+		PermissionCreateComment,
+		PermissionCreatePost,
+		PermissionEditAnyPost,
+		PermissionDeleteAnyPost,
+		PermissionPublishPost,
+		PermissionEditAnyComment,
+		PermissionDeleteAnyComment,
+		PermissionManageUsers,
+	},
+}
+
+// IsValidRole reports whether role is a known role name
+func IsValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// RolePermissions returns the permissions granted by a role
+func RolePermissions(role string) []Permission {
+	return rolePermissions[role]
+}
+
+// HasPermission reports whether the user's role grants the permission
+func (u User) HasPermission(permission Permission) bool {
+	for _, p := range rolePermissions[u.Role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+)
+
+// Session is a signed-in device. Every login starts a session; its refresh
+// tokens share the session ID as their FamilyID and its access tokens carry
+// the session ID in the "sid" claim.
+type Session struct {
+	ID         string     `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	UserID     string     `json:"user_id" gorm:"type:varchar(36);not null;index"`
+	DeviceName string     `json:"device_name" gorm:"type:varchar(100)"`
+	UserAgent  string     `json:"user_agent" gorm:"type:varchar(500)"`
+	IPAddress  string     `json:"ip_address" gorm:"type:varchar(45)"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+
+	// Relationships
+	User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	DeviceName string    `json:"device_name"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	Current    bool      `json:"current"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
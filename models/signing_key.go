@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+)
+
+// SigningKey is a key pair used to sign access tokens. Keys are shared through
+// the database so that every API instance signs and verifies with the same set.
+type SigningKey struct {
+	// ID is published as the "kid" header of tokens signed with this key
+	ID            string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	Algorithm     string    `json:"algorithm" gorm:"type:varchar(16);not null"`
+	PrivateKeyPEM string    `json:"-" gorm:"type:text;not null"`
+	PublicKeyPEM  string    `json:"public_key_pem" gorm:"type:text;not null"`
+	RetiresAt     time.Time `json:"retires_at" gorm:"index"`
+	ExpiresAt     time.Time `json:"expires_at" gorm:"index"`
+	CreatedAt     time.Time `json:"created_at"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+)
+
+// RecoveryCode is a hashed single-use code that can replace a TOTP code when
+// the user has lost access to their authenticator
+type RecoveryCode struct {
+	ID        string     `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	UserID    string     `json:"user_id" gorm:"type:varchar(36);not null;index"`
+	CodeHash  string     `json:"-" gorm:"type:varchar(64);not null;index"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+
+	// Relationships
+	User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+type TwoFactorConfirmRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// TwoFactorDisableRequest confirms turning off TOTP. The password is required
+// for accounts that have one; the code or recovery code always is.
+type TwoFactorDisableRequest struct {
+	Password     string `json:"password"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type TwoFactorRecoveryCodesRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code"`
+	RecoveryCode   string `json:"recovery_code"`
+}
@@ -7,13 +7,39 @@ import (
 )
 
 type User struct {
-	ID           string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
-	Username     string    `json:"username" gorm:"uniqueIndex;type:varchar(50);not null"`
-	Email        string    `json:"email" gorm:"uniqueIndex;type:varchar(100);not null"`
-	PasswordHash string    `json:"-" gorm:"type:varchar(255);not null"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
-	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
+	ID              string     `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	Username        string     `json:"username" gorm:"uniqueIndex;type:varchar(50);not null"`
+	Email           string     `json:"email" gorm:"uniqueIndex;type:varchar(100);not null"`
+	PasswordHash    string     `json:"-" gorm:"type:varchar(255);not null"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	Role            string     `json:"role" gorm:"type:varchar(20);not null;default:author"`
+	PendingEmail    *string    `json:"-" gorm:"type:varchar(100);null"`
+
+	// Two-factor authentication
+	TOTPSecret       string     `json:"-" gorm:"column:totp_secret;type:varchar(64)"`
+	TOTPEnabledAt    *time.Time `json:"-" gorm:"column:totp_enabled_at"`
+	TOTPLastUsedStep int64      `json:"-" gorm:"column:totp_last_used_step;not null;default:0"`
+
+	// Public profile
+	DisplayName string `json:"display_name" gorm:"type:varchar(100)"`
+	Bio         string `json:"bio" gorm:"type:text"`
+	Website     string `json:"website" gorm:"type:varchar(255)"`
+	AvatarURL   string `json:"avatar_url" gorm:"type:varchar(500)"`
+
+	// Account status
+	SuspendedAt           *time.Time `json:"suspended_at"`
+	SuspendedUntil        *time.Time `json:"suspended_until"`
+	SuspensionReason      string     `json:"suspension_reason" gorm:"type:varchar(500)"`
+	PasswordResetRequired bool       `json:"password_reset_required" gorm:"not null;default:false"`
+	PasswordChangedAt     *time.Time `json:"password_changed_at"`
+
+	// Self-service deletion
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at"`
+	AnonymizedAt        *time.Time `json:"anonymized_at"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 
 	// Relationships
 	Posts    []Post    `json:"posts,omitempty" gorm:"foreignKey:AuthorID"`
@@ -24,7 +50,9 @@ type User struct {
 type UserRegisterRequest struct {
 	Username string `json:"username" binding:"required,min=3,max=50"`
 	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=6"`
+	Password string `json:"password" binding:"required"`
+	// InviteCode is required while registration is invite-only
+	InviteCode string `json:"invite_code"`
 }
 
 type UserLoginRequest struct {
@@ -32,9 +60,144 @@ type UserLoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type MagicLinkRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type MagicLinkConsumeRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// UserResponse is the public summary of a user embedded in posts, comments, and likes
 type UserResponse struct {
-	ID        string    `json:"id"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	CreatedAt time.Time `json:"created_at"`
+	ID          string    `json:"id"`
+	Username    string    `json:"username"`
+	DisplayName string    `json:"display_name,omitempty"`
+	AvatarURL   string    `json:"avatar_url,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ProfileResponse is the authenticated user's own profile, including private fields
+type ProfileResponse struct {
+	ID               string     `json:"id"`
+	Username         string     `json:"username"`
+	Email            string     `json:"email"`
+	PendingEmail     *string    `json:"pending_email,omitempty"`
+	EmailVerifiedAt  *time.Time `json:"email_verified_at"`
+	Role             string     `json:"role"`
+	TwoFactorEnabled bool       `json:"two_factor_enabled"`
+	DisplayName      string     `json:"display_name"`
+	Bio              string     `json:"bio"`
+	Website          string     `json:"website"`
+	AvatarURL        string     `json:"avatar_url"`
+	// DeletionScheduledAt is when the account will be deleted unless the deletion is cancelled
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+// PublicProfileResponse is a user's profile as shown to everyone else
+type PublicProfileResponse struct {
+	ID          string    `json:"id"`
+	Username    string    `json:"username"`
+	DisplayName string    `json:"display_name"`
+	Bio         string    `json:"bio"`
+	Website     string    `json:"website"`
+	AvatarURL   string    `json:"avatar_url"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	FollowersCount int64 `json:"followers_count"`
+	FollowingCount int64 `json:"following_count"`
+	// IsFollowing is set when the viewer is signed in
+	IsFollowing *bool `json:"is_following,omitempty"`
+}
+
+// ProfileUpdateRequest holds the profile fields to change; omitted fields are
+// left as they are. Changing the email address needs CurrentPassword, or Code
+// or RecoveryCode for accounts without a password that use two-factor sign-in.
+type ProfileUpdateRequest struct {
+	Username        *string `json:"username" binding:"omitempty,min=3,max=50"`
+	Email           *string `json:"email" binding:"omitempty,email,max=100"`
+	CurrentPassword string  `json:"current_password"`
+	Code            string  `json:"code"`
+	RecoveryCode    string  `json:"recovery_code"`
+	DisplayName     *string `json:"display_name" binding:"omitempty,max=100"`
+	Bio             *string `json:"bio" binding:"omitempty,max=2000"`
+	Website         *string `json:"website" binding:"omitempty,http_url,max=255"`
+	AvatarURL       *string `json:"avatar_url" binding:"omitempty,http_url,max=500"`
+}
+
+// AccountDeletionRequest confirms a request to delete the authenticated user's
+// account. The password is required for accounts that have one.
+type AccountDeletionRequest struct {
+	Password string `json:"password"`
+}
+
+type SuspendUserRequest struct {
+	Reason    string     `json:"reason" binding:"required,max=500"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// AdminUserResponse is the user representation returned by admin endpoints
+type AdminUserResponse struct {
+	ID                    string     `json:"id"`
+	Username              string     `json:"username"`
+	Email                 string     `json:"email"`
+	EmailVerifiedAt       *time.Time `json:"email_verified_at"`
+	Role                  string     `json:"role"`
+	Status                string     `json:"status"`
+	SuspendedAt           *time.Time `json:"suspended_at"`
+	SuspendedUntil        *time.Time `json:"suspended_until"`
+	SuspensionReason      string     `json:"suspension_reason,omitempty"`
+	PasswordResetRequired bool       `json:"password_reset_required"`
+	CreatedAt             time.Time  `json:"created_at"`
+	UpdatedAt             time.Time  `json:"updated_at"`
+}
+
+type UpdateUserRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=user author editor moderator admin"`
+}
+
+// User account statuses reported to admins
+const (
+	UserStatusActive    = "active"
+	UserStatusSuspended = "suspended"
+)
+
+// IsSuspended reports whether the user is currently suspended
+func (u User) IsSuspended() bool {
+	if u.SuspendedAt == nil {
+		return false
+	}
+	return u.SuspendedUntil == nil || time.Now().Before(*u.SuspendedUntil)
+}
+
+// Status returns the account status of the user
+func (u User) Status() string {
+	if u.IsSuspended() {
+		return UserStatusSuspended
+	}
+	return UserStatusActive
 }
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin/binding"
+)
+
+func TestProfileUpdateRequestURLs(t *testing.T) {
+	tests := []struct {
+		name  string
+		url   string
+		valid bool
+	}{
+		{"https", "https://example.com/about", true},
+		{"http", "http://example.com", true},
+		{"javascript", "javascript:alert(1)", false},
+		{"javascript with slashes", "javascript://example.com/%0Aalert(1)", false},
+		{"data", "data:text/html,<script>alert(1)</script>", false},
+		{"ftp", "ftp://example.com/file", false},
+		{"relative", "/profile", false},
+		{"no scheme", "example.com", false},
+		{"too long", "https://example.com/" + strings.Repeat("a", 500), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := tt.url
+			for field, req := range map[string]ProfileUpdateRequest{
+				"website":    {Website: &url},
+				"avatar_url": {AvatarURL: &url},
+			} {
+				err := binding.Validator.ValidateStruct(req)
+				if tt.valid && err != nil {
+					t.Errorf("%s %q rejected: %v", field, tt.url, err)
+				}
+				if !tt.valid && err == nil {
+					t.Errorf("%s %q accepted", field, tt.url)
+				}
+			}
+		})
+	}
+}
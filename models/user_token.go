@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+)
+
+// Purposes for single-use user tokens
+const (
+	TokenPurposePasswordReset     = "password_reset"
+	TokenPurposeEmailVerification = "email_verification"
+	TokenPurposeEmailChange       = "email_change"
+	TokenPurposeTwoFactorLogin    = "two_factor_login"
+	TokenPurposeAccountUnlock     = "account_unlock"
+	TokenPurposeMagicLink         = "magic_link"
+)
+
+// UserToken is a hashed, expiring, single-use token emailed to a user
+type UserToken struct {
+	ID        string     `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	UserID    string     `json:"user_id" gorm:"type:varchar(36);not null;index"`
+	Purpose   string     `json:"purpose" gorm:"type:varchar(32);not null;index"`
+	TokenHash string     `json:"-" gorm:"uniqueIndex;type:varchar(64);not null"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	Attempts  int        `json:"attempts" gorm:"not null;default:0"`
+	CreatedAt time.Time  `json:"created_at"`
+
+	// Relationships
+	User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Purposes of WebAuthn challenges
+const (
+	WebAuthnPurposeRegistration = "registration"
+	WebAuthnPurposeLogin        = "login"
+)
+
+// WebAuthnCredential is a passkey or security key registered by a user. The
+// credential ID can be up to 1023 bytes, so lookups go through its hash.
+type WebAuthnCredential struct {
+	ID               string     `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	UserID           string     `json:"user_id" gorm:"type:varchar(36);not null;index"`
+	Name             string     `json:"name" gorm:"type:varchar(100);not null"`
+	CredentialID     string     `json:"credential_id" gorm:"type:text;not null"`
+	CredentialIDHash string     `json:"-" gorm:"type:varchar(64);uniqueIndex;not null"`
+	PublicKey        []byte     `json:"-" gorm:"type:blob;not null"`
+	Algorithm        int        `json:"algorithm" gorm:"not null"`
+	SignCount        uint32     `json:"sign_count" gorm:"not null;default:0"`
+	AAGUID           string     `json:"aaguid" gorm:"type:varchar(32)"`
+	Transports       string     `json:"transports" gorm:"type:varchar(100)"`
+	BackupEligible   bool       `json:"backup_eligible" gorm:"not null;default:false"`
+	BackupState      bool       `json:"backup_state" gorm:"not null;default:false"`
+	LastUsedAt       *time.Time `json:"last_used_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+
+	// Relationships
+	User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+// TransportList returns the transports the authenticator reported
+func (c WebAuthnCredential) TransportList() []string {
+	if c.Transports == "" {
+		return []string{}
+	}
+	return strings.Split(c.Transports, ",")
+}
+
+// WebAuthnChallenge is a pending registration or sign-in ceremony. UserID is
+// empty for sign-ins that let the authenticator pick the account.
+type WebAuthnChallenge struct {
+	ID            string     `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	UserID        *string    `json:"user_id" gorm:"type:varchar(36);index"`
+	Purpose       string     `json:"purpose" gorm:"type:varchar(32);not null"`
+	ChallengeHash string     `json:"-" gorm:"type:varchar(64);uniqueIndex;not null"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	UsedAt        *time.Time `json:"used_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// WebAuthnAttestation is the JSON form of a PublicKeyCredential returned by
+// navigator.credentials.create(), with binary fields base64url-encoded
+type WebAuthnAttestation struct {
+	ID       string `json:"id" binding:"required"`
+	Type     string `json:"type" binding:"required,eq=public-key"`
+	Response struct {
+		ClientDataJSON    string   `json:"clientDataJSON" binding:"required"`
+		AttestationObject string   `json:"attestationObject" binding:"required"`
+		Transports        []string `json:"transports"`
+	} `json:"response" binding:"required"`
+}
+
+// WebAuthnAssertion is the JSON form of a PublicKeyCredential returned by
+// navigator.credentials.get(), with binary fields base64url-encoded
+type WebAuthnAssertion struct {
+	ID       string `json:"id" binding:"required"`
+	Type     string `json:"type" binding:"required,eq=public-key"`
+	Response struct {
+		ClientDataJSON    string `json:"clientDataJSON" binding:"required"`
+		AuthenticatorData string `json:"authenticatorData" binding:"required"`
+		Signature         string `json:"signature" binding:"required"`
+		UserHandle        string `json:"userHandle"`
+	} `json:"response" binding:"required"`
+}
+
+type WebAuthnRegisterFinishRequest struct {
+	Name       string              `json:"name" binding:"max=100"`
+	Credential WebAuthnAttestation `json:"credential" binding:"required"`
+}
+
+type WebAuthnLoginBeginRequest struct {
+	Email string `json:"email" binding:"omitempty,email"`
+}
+
+type WebAuthnLoginFinishRequest struct {
+	Credential WebAuthnAssertion `json:"credential" binding:"required"`
+}
+
+type WebAuthnCredentialResponse struct {
+	ID             string     `json:"id"`
+	Name           string     `json:"name"`
+	Transports     []string   `json:"transports"`
+	BackupEligible bool       `json:"backup_eligible"`
+	BackupState    bool       `json:"backup_state"`
+	LastUsedAt     *time.Time `json:"last_used_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
@@ -0,0 +1,74 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"strconv"
+)
+
+// GitHubProvider signs users in with GitHub. BaseURL and APIURL can point at
+// a GitHub Enterprise server or a mock for testing.
+type GitHubProvider struct {
+	ClientID     string
+	ClientSecret string
+	BaseURL      string
+	APIURL       string
+}
+
+// Name returns "github"
+func (p *GitHubProvider) Name() string {
+	return "github"
+}
+
+// AuthCodeURL returns the GitHub authorization URL
+func (p *GitHubProvider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge, redirectURI string) (string, error) {
+	return authCodeURL(p.BaseURL+"/login/oauth/authorize", p.ClientID, redirectURI, "read:user user:email", state, codeChallenge, nil)
+}
+
+// Exchange redeems the code and reads the user and their primary email from the GitHub API
+func (p *GitHubProvider) Exchange(ctx context.Context, code, codeVerifier, nonce, redirectURI string) (*Identity, error) {
+	token, err := exchangeCode(ctx, p.BaseURL+"/login/oauth/access_token", p.ClientID, p.ClientSecret, code, codeVerifier, redirectURI)
+	if err != nil {
+		return nil, err
+	}
+
+	var user struct {
+		ID        int64  `json:"id"`
+		Login     string `json:"login"`
+		Name      string `json:"name"`
+		AvatarURL string `json:"avatar_url"`
+	}
+	if err := getJSON(ctx, p.APIURL+"/user", token.AccessToken, &user); err != nil {
+		return nil, err
+	}
+	if user.ID == 0 {
+		return nil, errors.New("GitHub user has no ID")
+	}
+
+	identity := &Identity{
+		Provider:  p.Name(),
+		Subject:   strconv.FormatInt(user.ID, 10),
+		Username:  user.Login,
+		Name:      user.Name,
+		AvatarURL: user.AvatarURL,
+	}
+
+	// The profile email may be hidden, so read the verified primary address instead
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := getJSON(ctx, p.APIURL+"/user/emails", token.AccessToken, &emails); err != nil {
+		return nil, err
+	}
+	for _, email := range emails {
+		if email.Primary {
+			identity.Email = email.Email
+			identity.EmailVerified = email.Verified
+			break
+		}
+	}
+
+	return identity, nil
+}
@@ -0,0 +1,204 @@
+// Package oauth implements the authorization code flow with PKCE against
+// external identity providers: GitHub and any OpenID Connect provider.
+package oauth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"blog-api/config"
+)
+
+// Identity is the account a user signed in with at a provider
+type Identity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Username      string
+	Name          string
+	AvatarURL     string
+}
+
+// Provider is an external identity provider
+type Provider interface {
+	// Name returns the name used in URLs, such as "github"
+	Name() string
+	// AuthCodeURL returns the URL the user is sent to in order to sign in
+	AuthCodeURL(ctx context.Context, state, nonce, codeChallenge, redirectURI string) (string, error)
+	// Exchange redeems an authorization code and returns the signed-in identity
+	Exchange(ctx context.Context, code, codeVerifier, nonce, redirectURI string) (*Identity, error)
+}
+
+// Providers holds the configured providers by name
+var Providers = map[string]Provider{}
+
+// httpClient is used for all requests to providers
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// Setup registers the providers whose client IDs are set in the environment
+func Setup() {
+	Providers = map[string]Provider{}
+
+	if clientID := config.GetEnv("GITHUB_CLIENT_ID", ""); clientID != "" {
+		register(&GitHubProvider{
+			ClientID:     clientID,
+			ClientSecret: config.GetEnv("GITHUB_CLIENT_SECRET", ""),
+			BaseURL:      strings.TrimRight(config.GetEnv("GITHUB_BASE_URL", "https://github.com"), "/"),
+			APIURL:       strings.TrimRight(config.GetEnv("GITHUB_API_URL", "https://api.github.com"), "/"),
+		})
+	}
+
+	if clientID := config.GetEnv("OIDC_CLIENT_ID", ""); clientID != "" {
+		issuer := config.GetEnv("OIDC_ISSUER_URL", "")
+		if issuer == "" {
+			log.Printf("OIDC_CLIENT_ID is set but OIDC_ISSUER_URL is not, skipping OIDC provider")
+		} else {
+			register(&OIDCProvider{
+				ProviderName: config.GetEnv("OIDC_PROVIDER_NAME", "oidc"),
+				IssuerURL:    strings.TrimRight(issuer, "/"),
+				ClientID:     clientID,
+				ClientSecret: config.GetEnv("OIDC_CLIENT_SECRET", ""),
+				Scopes:       strings.Fields(config.GetEnv("OIDC_SCOPES", "openid email profile")),
+			})
+		}
+	}
+}
+
+// register adds a provider to Providers
+func register(provider Provider) {
+	Providers[provider.Name()] = provider
+	log.Printf("Enabled OAuth provider %s", provider.Name())
+}
+
+// Get returns the provider with the given name
+func Get(name string) (Provider, bool) {
+	provider, ok := Providers[name]
+	return provider, ok
+}
+
+// Names returns the names of the configured providers in alphabetical order
+func Names() []string {
+	names := make([]string, 0, len(Providers))
+	for name := range Providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CodeChallenge returns the S256 PKCE code challenge for a code verifier
+func CodeChallenge(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// tokenResponse is the token endpoint response
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// authCodeURL builds an authorization request URL
+func authCodeURL(endpoint, clientID, redirectURI, scope, state, codeChallenge string, extra url.Values) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	query := u.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", clientID)
+	query.Set("redirect_uri", redirectURI)
+	query.Set("scope", scope)
+	query.Set("state", state)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+	for key, values := range extra {
+		query[key] = values
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+// exchangeCode redeems an authorization code at a token endpoint
+func exchangeCode(ctx context.Context, tokenURL, clientID, clientSecret, code, codeVerifier, redirectURI string) (*tokenResponse, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+		"client_id":     {clientID},
+		"code_verifier": {codeVerifier},
+	}
+	if clientSecret != "" {
+		form.Set("client_secret", clientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var token tokenResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&token); err != nil {
+		return nil, fmt.Errorf("token endpoint returned %s", resp.Status)
+	}
+	if token.Error != "" {
+		return nil, fmt.Errorf("token endpoint error %s: %s", token.Error, token.ErrorDescription)
+	}
+	if resp.StatusCode != http.StatusOK || token.AccessToken == "" {
+		return nil, fmt.Errorf("token endpoint returned %s", resp.Status)
+	}
+
+	return &token, nil
+}
+
+// getJSON fetches a JSON document, sending the access token if one is given
+func getJSON(ctx context.Context, endpoint, accessToken string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned %s", endpoint, resp.Status)
+	}
+
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v); err != nil {
+		return errors.New("invalid JSON from " + endpoint)
+	}
+	return nil
+}
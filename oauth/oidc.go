@@ -0,0 +1,301 @@
+package oauth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// jwksRefreshInterval limits how often an unknown kid triggers a JWKS refetch
+const jwksRefreshInterval = time.Minute
+
+// OIDCProvider signs users in with any OpenID Connect provider. Endpoints are
+// read from the issuer's discovery document on first use.
+type OIDCProvider struct {
+	ProviderName string
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+
+	mu          sync.Mutex
+	discovery   *oidcDiscovery
+	keys        map[string]crypto.PublicKey
+	keysFetched time.Time
+}
+
+// oidcDiscovery is the part of the discovery document that is used
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// jsonWebKey is a public key from a JWKS document
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// Name returns the configured provider name
+func (p *OIDCProvider) Name() string {
+	return p.ProviderName
+}
+
+// AuthCodeURL returns the provider's authorization URL
+func (p *OIDCProvider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge, redirectURI string) (string, error) {
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return authCodeURL(discovery.AuthorizationEndpoint, p.ClientID, redirectURI, strings.Join(p.Scopes, " "), state, codeChallenge,
+		url.Values{"nonce": {nonce}})
+}
+
+// Exchange redeems the code and verifies the returned ID token. Claims missing
+// from the ID token are read from the userinfo endpoint.
+func (p *OIDCProvider) Exchange(ctx context.Context, code, codeVerifier, nonce, redirectURI string) (*Identity, error) {
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := exchangeCode(ctx, discovery.TokenEndpoint, p.ClientID, p.ClientSecret, code, codeVerifier, redirectURI)
+	if err != nil {
+		return nil, err
+	}
+	if token.IDToken == "" {
+		return nil, errors.New("token response has no id_token")
+	}
+
+	claims, err := p.verifyIDToken(ctx, discovery, token.IDToken, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := claims["email"]; !ok && discovery.UserinfoEndpoint != "" {
+		var userinfo map[string]interface{}
+		if err := getJSON(ctx, discovery.UserinfoEndpoint, token.AccessToken, &userinfo); err != nil {
+			return nil, err
+		}
+		// The userinfo response must describe the same user as the ID token
+		if userinfo["sub"] != claims["sub"] {
+			return nil, errors.New("userinfo subject does not match ID token")
+		}
+		for key, value := range userinfo {
+			if _, ok := claims[key]; !ok {
+				claims[key] = value
+			}
+		}
+	}
+
+	identity := &Identity{Provider: p.Name()}
+	identity.Subject, _ = claims["sub"].(string)
+	identity.Email, _ = claims["email"].(string)
+	identity.Name, _ = claims["name"].(string)
+	identity.Username, _ = claims["preferred_username"].(string)
+	identity.AvatarURL, _ = claims["picture"].(string)
+
+	// Some providers send email_verified as a string
+	switch verified := claims["email_verified"].(type) {
+	case bool:
+		identity.EmailVerified = verified
+	case string:
+		identity.EmailVerified = verified == "true"
+	}
+
+	if identity.Subject == "" {
+		return nil, errors.New("ID token has no subject")
+	}
+
+	return identity, nil
+}
+
+// verifyIDToken checks the ID token signature, issuer, audience, expiry, and nonce
+func (p *OIDCProvider) verifyIDToken(ctx context.Context, discovery *oidcDiscovery, idToken, nonce string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(idToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.publicKey(ctx, discovery, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}),
+		jwt.WithIssuer(discovery.Issuer),
+		jwt.WithAudience(p.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID token: %w", err)
+	}
+
+	if tokenNonce, _ := claims["nonce"].(string); tokenNonce != nonce {
+		return nil, errors.New("invalid ID token: nonce mismatch")
+	}
+
+	return claims, nil
+}
+
+// discover fetches and caches the discovery document
+func (p *OIDCProvider) discover(ctx context.Context) (*oidcDiscovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	var discovery oidcDiscovery
+	if err := getJSON(ctx, p.IssuerURL+"/.well-known/openid-configuration", "", &discovery); err != nil {
+		return nil, err
+	}
+	if strings.TrimRight(discovery.Issuer, "/") != p.IssuerURL {
+		return nil, fmt.Errorf("discovery document issuer %q does not match %q", discovery.Issuer, p.IssuerURL)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return nil, errors.New("discovery document is missing required endpoints")
+	}
+
+	p.discovery = &discovery
+	return p.discovery, nil
+}
+
+// publicKey returns the signing key with the given kid, refetching the JWKS
+// when the kid is unknown in case the provider rotated its keys
+func (p *OIDCProvider) publicKey(ctx context.Context, discovery *oidcDiscovery, kid string) (crypto.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.findKey(kid); ok {
+		return key, nil
+	}
+	if time.Since(p.keysFetched) < jwksRefreshInterval {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := getJSON(ctx, discovery.JWKSURI, "", &jwks); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	p.keys = keys
+	p.keysFetched = time.Now()
+
+	if key, ok := p.findKey(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// findKey looks up a cached key. A token without a kid matches when the
+// provider publishes exactly one key.
+func (p *OIDCProvider) findKey(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+// publicKey decodes an RSA, EC, or Ed25519 JWK
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("RSA exponent too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		key := &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("EC point is not on the curve")
+		}
+		return key, nil
+
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+// decodeBigInt decodes a base64url encoded big-endian integer
+func decodeBigInt(value string) (*big.Int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(raw) == 0 {
+		return nil, errors.New("invalid JWK integer")
+	}
+	return new(big.Int).SetBytes(raw), nil
+}
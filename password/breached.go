@@ -0,0 +1,68 @@
+package password
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// BreachedList is a set of SHA-1 hashes of passwords known from data breaches
+type BreachedList struct {
+	hashes map[[sha1.Size]byte]struct{}
+}
+
+// Breached is the list Check consults, or nil if none is loaded
+var Breached *BreachedList
+
+// LoadBreachedList reads a file with one uppercase or lowercase hex SHA-1 hash
+// per line. An optional ":count" suffix, as in the Have I Been Pwned
+// downloads, is ignored, as are blank lines and lines starting with #.
+func LoadBreachedList(path string) (*BreachedList, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	list := &BreachedList{hashes: make(map[[sha1.Size]byte]struct{})}
+	scanner := bufio.NewScanner(file)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if i := strings.IndexByte(text, ':'); i >= 0 {
+			text = text[:i]
+		}
+
+		raw, err := hex.DecodeString(text)
+		if err != nil || len(raw) != sha1.Size {
+			return nil, fmt.Errorf("line %d: expected a hex SHA-1 hash", line)
+		}
+
+		var sum [sha1.Size]byte
+		copy(sum[:], raw)
+		list.hashes[sum] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// Contains reports whether the password is in the list
+func (l *BreachedList) Contains(password string) bool {
+	_, found := l.hashes[sha1.Sum([]byte(password))]
+	return found
+}
+
+// Len returns the number of hashes in the list
+func (l *BreachedList) Len() int {
+	return len(l.hashes)
+}
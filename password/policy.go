@@ -0,0 +1,156 @@
+// Package password checks new passwords against the configured password
+// policy and a locally loaded list of breached passwords.
+package password
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"blog-api/config"
+)
+
+// Rules a password can fail
+const (
+	RuleMinLength    = "min_length"
+	RuleMaxLength    = "max_length"
+	RuleUppercase    = "uppercase"
+	RuleLowercase    = "lowercase"
+	RuleDigit        = "digit"
+	RuleSymbol       = "symbol"
+	RulePersonalInfo = "personal_info"
+	RuleBreached     = "breached"
+	RuleReused       = "reused"
+)
+
+// Violation is a failed password rule
+type Violation struct {
+	Rule    string
+	Message string
+}
+
+func (v *Violation) Error() string {
+	return v.Message
+}
+
+// Policy describes what passwords are accepted
+type Policy struct {
+	MinLength            int
+	MaxLength            int
+	RequireUppercase     bool
+	RequireLowercase     bool
+	RequireDigit         bool
+	RequireSymbol        bool
+	DisallowPersonalInfo bool
+	// HistorySize is how many previous passwords cannot be reused
+	HistorySize int
+}
+
+// DefaultPolicy is the policy applied by Check
+var DefaultPolicy = Policy{MinLength: 8, MaxLength: 72, DisallowPersonalInfo: true, HistorySize: 5}
+
+// Setup reads the password policy from the environment and loads the breached password list
+func Setup() {
+	DefaultPolicy = Policy{
+		MinLength:            config.GetEnvInt("PASSWORD_MIN_LENGTH", 8),
+		MaxLength:            config.GetEnvInt("PASSWORD_MAX_LENGTH", 72),
+		RequireUppercase:     config.GetEnvBool("PASSWORD_REQUIRE_UPPERCASE", false),
+		RequireLowercase:     config.GetEnvBool("PASSWORD_REQUIRE_LOWERCASE", false),
+		RequireDigit:         config.GetEnvBool("PASSWORD_REQUIRE_DIGIT", false),
+		RequireSymbol:        config.GetEnvBool("PASSWORD_REQUIRE_SYMBOL", false),
+		DisallowPersonalInfo: config.GetEnvBool("PASSWORD_DISALLOW_PERSONAL_INFO", true),
+		HistorySize:          config.GetEnvInt("PASSWORD_HISTORY_SIZE", 5),
+	}
+
+	// bcrypt only uses the first 72 bytes
+	if DefaultPolicy.MaxLength <= 0 || DefaultPolicy.MaxLength > 72 {
+		DefaultPolicy.MaxLength = 72
+	}
+
+	if path := config.GetEnv("BREACHED_PASSWORDS_FILE", ""); path != "" {
+		list, err := LoadBreachedList(path)
+		if err != nil {
+			log.Fatalf("Failed to load breached password list %s: %v", path, err)
+		}
+		Breached = list
+		log.Printf("Loaded %d breached password hashes", list.Len())
+	}
+}
+
+// Check applies DefaultPolicy and the breached password list to a new
+// password for the user with the given username and email. It returns a
+// *Violation for the first rule the password fails.
+func Check(password, username, email string) error {
+	if err := DefaultPolicy.Check(password, username, email); err != nil {
+		return err
+	}
+
+	if Breached != nil && Breached.Contains(password) {
+		return &Violation{Rule: RuleBreached, Message: "Password has appeared in a data breach, please choose another"}
+	}
+
+	return nil
+}
+
+// Check applies the policy to a password
+func (p Policy) Check(password, username, email string) error {
+	if length := utf8.RuneCountInString(password); length < p.MinLength {
+		return &Violation{Rule: RuleMinLength, Message: fmt.Sprintf("Password must be at least %d characters", p.MinLength)}
+	}
+	if len(password) > p.MaxLength {
+		return &Violation{Rule: RuleMaxLength, Message: fmt.Sprintf("Password must be at most %d bytes", p.MaxLength)}
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+
+	switch {
+	case p.RequireUppercase && !hasUpper:
+		return &Violation{Rule: RuleUppercase, Message: "Password must contain an uppercase letter"}
+	case p.RequireLowercase && !hasLower:
+		return &Violation{Rule: RuleLowercase, Message: "Password must contain a lowercase letter"}
+	case p.RequireDigit && !hasDigit:
+		return &Violation{Rule: RuleDigit, Message: "Password must contain a digit"}
+	case p.RequireSymbol && !hasSymbol:
+		return &Violation{Rule: RuleSymbol, Message: "Password must contain a symbol"}
+	}
+
+	if p.DisallowPersonalInfo && containsPersonalInfo(password, username, email) {
+		return &Violation{Rule: RulePersonalInfo, Message: "Password must not contain your username or email address"}
+	}
+
+	return nil
+}
+
+// containsPersonalInfo reports whether the password contains the username,
+// the email address, or the part of the email address before the @
+func containsPersonalInfo(password, username, email string) bool {
+	password = strings.ToLower(password)
+
+	candidates := []string{username, email}
+	if at := strings.LastIndex(email, "@"); at > 0 {
+		candidates = append(candidates, email[:at])
+	}
+
+	for _, candidate := range candidates {
+		candidate = strings.ToLower(strings.TrimSpace(candidate))
+		// Very short names would reject too many unrelated passwords
+		if utf8.RuneCountInString(candidate) >= 3 && strings.Contains(password, candidate) {
+			return true
+		}
+	}
+	return false
+}
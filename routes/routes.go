@@ -1,8 +1,11 @@
 package routes
 
 import (
+	"time"
+
 	"blog-api/handlers"
 	"blog-api/middleware"
+	"blog-api/models"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/time/rate"
@@ -29,14 +32,44 @@ func SetupRoutes(logger interface{}) *gin.Engine {
 		})
 	})
 
+	// Public keys for verifying access tokens
+	r.GET("/.well-known/jwks.json", handlers.GetJWKS)
+
 	// API v1 routes
 	v1 := r.Group("/api/v1")
 	{
 		// Auth routes (no authentication required)
+		// Credential endpoints get a much smaller per-IP budget than the global limit
+		credentialLimit := middleware.RateLimitMiddleware(rate.Every(10*time.Second), 5) // 5 requests per burst, then 1 every 10 seconds per IP
+
 		auth := v1.Group("/auth")
 		{
-			auth.POST("/register", handlers.Register)
-			auth.POST("/login", handlers.Login)
+			auth.GET("/registration", handlers.GetRegistrationSettings)
+			auth.POST("/register", credentialLimit, handlers.Register)
+			auth.POST("/login", credentialLimit, handlers.Login)
+			auth.POST("/refresh", handlers.RefreshToken)
+			auth.POST("/logout", handlers.Logout)
+			auth.POST("/password/forgot", credentialLimit, handlers.ForgotPassword)
+			auth.POST("/password/reset", handlers.ResetPassword)
+			auth.GET("/verify-email", handlers.VerifyEmail)
+			auth.POST("/verify-email", handlers.VerifyEmail)
+			auth.POST("/verify-email/resend", handlers.ResendVerificationEmail)
+			auth.POST("/2fa/verify", credentialLimit, handlers.VerifyTwoFactorLogin)
+			auth.POST("/unlock", handlers.UnlockAccount)
+			auth.POST("/magic-link", credentialLimit, handlers.RequestMagicLink)
+			auth.POST("/magic-link/consume", credentialLimit, handlers.ConsumeMagicLink)
+
+			// Sign in with an external provider
+			auth.GET("/oauth/providers", handlers.ListOAuthProviders)
+			auth.GET("/oauth/:provider/authorize", handlers.StartOAuthLogin)
+			auth.GET("/oauth/:provider/callback", credentialLimit, handlers.OAuthCallback)
+			auth.POST("/oauth/:provider/callback", credentialLimit, handlers.OAuthCallback)
+
+			// Passkeys: registration needs a signed-in user, sign-in does not
+			auth.POST("/webauthn/register/begin", middleware.AuthMiddleware(), middleware.RequireSessionAuth(), handlers.BeginPasskeyRegistration)
+			auth.POST("/webauthn/register/finish", middleware.AuthMiddleware(), middleware.RequireSessionAuth(), handlers.FinishPasskeyRegistration)
+			auth.POST("/webauthn/login/begin", credentialLimit, handlers.BeginPasskeyLogin)
+			auth.POST("/webauthn/login/finish", credentialLimit, handlers.FinishPasskeyLogin)
 		}
 
 		// Public routes (no authentication required)
@@ -44,9 +77,15 @@ func SetupRoutes(logger interface{}) *gin.Engine {
 		{
 			// Posts (public read access)
 			public.GET("/posts", handlers.GetPosts)
-			public.GET("/posts/:id", handlers.GetPost)
-			public.GET("/posts/:id/comments", handlers.GetComments)
-			public.GET("/posts/:id/likes", handlers.GetPostLikes)
+			public.GET("/posts/:id", middleware.OptionalAuthMiddleware(), handlers.GetPost)
+			public.GET("/posts/by-slug/:slug", middleware.OptionalAuthMiddleware(), handlers.GetPostBySlug)
+			public.GET("/posts/:id/comments", middleware.OptionalAuthMiddleware(), handlers.GetComments)
+			public.GET("/posts/:id/likes", middleware.OptionalAuthMiddleware(), handlers.GetPostLikes)
+
+			// Public user profiles
+			public.GET("/users/:username", middleware.OptionalAuthMiddleware(), handlers.GetUserProfile)
+			public.GET("/users/:username/followers", handlers.GetFollowers)
+			public.GET("/users/:username/following", handlers.GetFollowing)
 		}
 
 		// Protected routes (authentication required)
@@ -55,25 +94,102 @@ func SetupRoutes(logger interface{}) *gin.Engine {
 		protected.Use(middleware.UserRateLimitMiddleware(rate.Every(1), 5)) // 5 requests per second per user
 		{
 			// User profile
-			protected.GET("/profile", handlers.GetProfile)
+			protected.GET("/profile", middleware.RequireScope(models.ScopeProfileRead), handlers.GetProfile)
+			protected.PATCH("/profile", middleware.RequireSessionAuth(), handlers.UpdateProfile)
+			protected.DELETE("/profile", middleware.RequireSessionAuth(), handlers.DeleteAccount)
+			protected.POST("/profile/restore", middleware.RequireSessionAuth(), handlers.CancelAccountDeletion)
+			protected.GET("/profile/export", middleware.RequireSessionAuth(), handlers.ExportProfile)
+			protected.POST("/profile/password", middleware.RequireSessionAuth(), handlers.ChangePassword)
+
+			// Two-factor authentication
+			protected.POST("/profile/2fa/setup", middleware.RequireSessionAuth(), handlers.SetupTwoFactor)
+			protected.POST("/profile/2fa/confirm", middleware.RequireSessionAuth(), handlers.ConfirmTwoFactor)
+			protected.POST("/profile/2fa/disable", middleware.RequireSessionAuth(), handlers.DisableTwoFactor)
+			protected.POST("/profile/2fa/recovery-codes", middleware.RequireSessionAuth(), handlers.RegenerateRecoveryCodes)
+
+			// API keys
+			protected.GET("/profile/api-keys", middleware.RequireSessionAuth(), handlers.ListAPIKeys)
+			protected.POST("/profile/api-keys", middleware.RequireSessionAuth(), handlers.CreateAPIKey)
+			protected.DELETE("/profile/api-keys/:id", middleware.RequireSessionAuth(), handlers.RevokeAPIKey)
+
+			// Signed-in sessions
+			protected.GET("/profile/sessions", middleware.RequireSessionAuth(), handlers.ListSessions)
+			protected.DELETE("/profile/sessions", middleware.RequireSessionAuth(), handlers.RevokeOtherSessions)
+			protected.DELETE("/profile/sessions/:id", middleware.RequireSessionAuth(), handlers.RevokeSession)
+
+			// Linked sign-in providers
+			protected.GET("/profile/identities", middleware.RequireSessionAuth(), handlers.ListOAuthIdentities)
+			protected.POST("/profile/identities/:provider", middleware.RequireSessionAuth(), handlers.LinkOAuthIdentity)
+			protected.DELETE("/profile/identities/:id", middleware.RequireSessionAuth(), handlers.UnlinkOAuthIdentity)
+
+			// Passkeys
+			protected.GET("/profile/passkeys", middleware.RequireSessionAuth(), handlers.ListPasskeys)
+			protected.DELETE("/profile/passkeys/:id", middleware.RequireSessionAuth(), handlers.DeletePasskey)
+
+			// Follows and the home feed
+			protected.POST("/users/:username/follow", middleware.RequireScope(models.ScopeProfileWrite), handlers.FollowUser)
+			protected.POST("/users/:username/unfollow", middleware.RequireScope(models.ScopeProfileWrite), handlers.UnfollowUser)
+			protected.GET("/feed", middleware.RequireScope(models.ScopePostsRead), handlers.GetFeed)
+
+			// Blocked and muted users
+			protected.GET("/profile/blocks", middleware.RequireScope(models.ScopeProfileRead), handlers.ListBlocks)
+			protected.GET("/profile/mutes", middleware.RequireScope(models.ScopeProfileRead), handlers.ListMutes)
+			protected.POST("/users/:username/block", middleware.RequireScope(models.ScopeProfileWrite), handlers.BlockUser)
+			protected.POST("/users/:username/unblock", middleware.RequireScope(models.ScopeProfileWrite), handlers.UnblockUser)
+			protected.POST("/users/:username/mute", middleware.RequireScope(models.ScopeProfileWrite), handlers.MuteUser)
+			protected.POST("/users/:username/unmute", middleware.RequireScope(models.ScopeProfileWrite), handlers.UnmuteUser)
 
 			// Posts (authenticated)
-			protected.POST("/posts", handlers.CreatePost)
-			protected.PUT("/posts/:id", handlers.UpdatePost)
-			protected.DELETE("/posts/:id", handlers.DeletePost)
+			protected.POST("/posts", middleware.RequireScope(models.ScopePostsWrite), middleware.RequirePermission(models.PermissionCreatePost), middleware.RequireVerifiedEmail(), handlers.CreatePost)
+			protected.PUT("/posts/:id", middleware.RequireScope(models.ScopePostsWrite), handlers.UpdatePost)
+			protected.DELETE("/posts/:id", middleware.RequireScope(models.ScopePostsWrite), handlers.DeletePost)
+			protected.POST("/posts/:id/status", middleware.RequireScope(models.ScopePostsWrite), handlers.UpdatePostStatus)
+			protected.GET("/profile/posts", middleware.RequireScope(models.ScopePostsRead), handlers.GetMyPosts)
+			protected.GET("/posts/:id/revisions", middleware.RequireScope(models.ScopePostsRead), handlers.ListPostRevisions)
+			protected.GET("/posts/:id/revisions/diff", middleware.RequireScope(models.ScopePostsRead), handlers.DiffPostRevisions)
+			protected.GET("/posts/:id/revisions/:number", middleware.RequireScope(models.ScopePostsRead), handlers.GetPostRevision)
+			protected.POST("/posts/:id/revisions/:number/restore", middleware.RequireScope(models.ScopePostsWrite), handlers.RestorePostRevision)
+			protected.GET("/posts/scheduled", middleware.RequireScope(models.ScopePostsRead), handlers.GetScheduledPosts)
+			protected.PUT("/posts/:id/schedule", middleware.RequireScope(models.ScopePostsWrite), handlers.ReschedulePost)
+			protected.GET("/posts/review", middleware.RequireScope(models.ScopePostsRead), middleware.RequirePermission(models.PermissionEditAnyPost), handlers.GetReviewQueue)
 
 			// Comments (authenticated)
-			protected.POST("/posts/:id/comments", handlers.CreateComment)
-			protected.PUT("/comments/:id", handlers.UpdateComment)
-			protected.DELETE("/comments/:id", handlers.DeleteComment)
+			protected.POST("/posts/:id/comments", middleware.RequireScope(models.ScopeCommentsWrite), middleware.RequirePermission(models.PermissionCreateComment), middleware.RequireVerifiedEmail(), handlers.CreateComment)
+			protected.PUT("/comments/:id", middleware.RequireScope(models.ScopeCommentsWrite), handlers.UpdateComment)
+			protected.DELETE("/comments/:id", middleware.RequireScope(models.ScopeCommentsWrite), handlers.DeleteComment)
 
 			// Replies (authenticated)
-			protected.POST("/comments/:id/reply", handlers.ReplyToComment)
+			protected.POST("/comments/:id/reply", middleware.RequireScope(models.ScopeCommentsWrite), middleware.RequirePermission(models.PermissionCreateComment), middleware.RequireVerifiedEmail(), handlers.ReplyToComment)
 
 			// Likes (authenticated)
-			protected.POST("/posts/:id/like", handlers.LikePost)
-			protected.POST("/posts/:id/unlike", handlers.UnlikePost)
-			protected.GET("/posts/:id/like-status", handlers.CheckUserLike)
+			protected.POST("/posts/:id/like", middleware.RequireScope(models.ScopeLikesWrite), handlers.LikePost)
+			protected.POST("/posts/:id/unlike", middleware.RequireScope(models.ScopeLikesWrite), handlers.UnlikePost)
+			protected.GET("/posts/:id/like-status", middleware.RequireScope(models.ScopeLikesRead), handlers.CheckUserLike)
+		}
+
+		// Admin routes (admin role required)
+		admin := v1.Group("/admin")
+		admin.Use(middleware.AuthMiddleware())
+		admin.Use(middleware.RequireSessionAuth())
+		admin.Use(middleware.RequirePermission(models.PermissionManageUsers))
+		{
+			admin.GET("/users", handlers.ListUsers)
+			admin.GET("/users/:id", handlers.GetUser)
+			admin.PUT("/users/:id/role", handlers.UpdateUserRole)
+			admin.POST("/users/:id/suspend", handlers.SuspendUser)
+			admin.POST("/users/:id/unsuspend", handlers.UnsuspendUser)
+			admin.POST("/users/:id/force-password-reset", handlers.ForcePasswordReset)
+			admin.POST("/users/:id/unlock", handlers.UnlockUser)
+			admin.DELETE("/users/:id", handlers.DeleteUser)
+
+			// Invite codes for invite-only registration
+			admin.GET("/invites", handlers.ListInvites)
+			admin.POST("/invites", handlers.CreateInvite)
+			admin.DELETE("/invites/:id", handlers.RevokeInvite)
+
+			// Security audit log
+			admin.GET("/audit-logs", handlers.ListAuditLogs)
+			admin.GET("/audit-logs/export", handlers.ExportAuditLogs)
 		}
 	}
 
@@ -0,0 +1,106 @@
+// Package slug turns titles into short, URL-safe identifiers such as
+// "hello-world". Accented letters lose their marks and common letters from
+// other alphabets are transliterated to ASCII; anything else is dropped.
+package slug
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxLength is the maximum length of a slug
+const MaxLength = 100
+
+// transliterations spells out letters that do not decompose into an ASCII
+// letter and combining marks
+var transliterations = map[rune]string{
+	// Latin
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'ł': "l", 'đ': "d", 'ð': "d",
+	'þ': "th", 'ı': "i", 'ħ': "h", 'ŋ': "ng",
+	// Greek
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i",
+	'θ': "th", 'ι': "i", 'κ': "k", 'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x",
+	'ο': "o", 'π': "p", 'ρ': "r", 'σ': "s", 'ς': "s", 'τ': "t", 'υ': "y",
+	'φ': "f", 'χ': "ch", 'ψ': "ps", 'ω': "o",
+	// Cyrillic
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'ґ': "g", 'д': "d", 'е': "e",
+	'ё': "yo", 'є': "ye", 'ж': "zh", 'з': "z", 'и': "i", 'і': "i", 'ї': "yi",
+	'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p",
+	'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e",
+	'ю': "yu", 'я': "ya",
+}
+
+// Make returns the slug for s, or "" when s has no letters or digits that can
+// be written in ASCII
+func Make(s string) string {
+	var b strings.Builder
+	pendingHyphen := false
+	write := func(part string) {
+		if pendingHyphen && b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		pendingHyphen = false
+		b.WriteString(part)
+	}
+
+	for _, r := range strings.ToLower(norm.NFC.String(s)) {
+		if t, ok := transliterations[r]; ok {
+			write(t)
+			continue
+		}
+
+		// Decompose accented letters into a base letter and combining marks, and
+		// compatibility characters such as "²" into their plain form
+		for _, d := range norm.NFKD.String(string(r)) {
+			d = unicode.ToLower(d)
+			switch {
+			case d >= 'a' && d <= 'z', d >= '0' && d <= '9':
+				write(string(d))
+			case unicode.Is(unicode.Mn, d):
+				// Drop combining marks
+			default:
+				if t, ok := transliterations[d]; ok {
+					write(t)
+				} else {
+					pendingHyphen = true
+				}
+			}
+		}
+	}
+
+	return truncate(b.String())
+}
+
+// Valid reports whether s is a well-formed slug: lowercase ASCII letters and
+// digits in groups separated by single hyphens, at most MaxLength long
+func Valid(s string) bool {
+	if s == "" || len(s) > MaxLength {
+		return false
+	}
+	for _, part := range strings.Split(s, "-") {
+		if part == "" {
+			return false
+		}
+		for _, r := range part {
+			if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// truncate shortens s to at most MaxLength, cutting at a hyphen when possible
+func truncate(s string) string {
+	if len(s) <= MaxLength {
+		return s
+	}
+	s = s[:MaxLength]
+	if i := strings.LastIndexByte(s, '-'); i > 0 {
+		s = s[:i]
+	}
+	return strings.TrimRight(s, "-")
+}
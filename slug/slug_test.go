@@ -0,0 +1,109 @@
+package slug
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		name  string
+		title string
+		want  string
+	}{
+		{"plain", "Hello World", "hello-world"},
+		{"punctuation", "Hello, World! (Part 2)", "hello-world-part-2"},
+		{"surrounding separators", "  --Hello--  ", "hello"},
+		{"underscores", "snake_case_title", "snake-case-title"},
+		{"digits", "Go 1.21 release notes", "go-1-21-release-notes"},
+		{"accents", "Crème brûlée à la française", "creme-brulee-a-la-francaise"},
+		{"decomposed accents", "Crème", "creme"},
+		{"german", "Straße über Öl", "strasse-uber-ol"},
+		{"nordic", "Smørrebrød og æbleskiver", "smorrebrod-og-aebleskiver"},
+		{"polish", "Łódź", "lodz"},
+		{"icelandic", "Þórður", "thordur"},
+		{"turkish dotless i", "Işık", "isik"},
+		{"greek", "Καλημέρα κόσμε", "kalimera-kosme"},
+		{"greek final sigma", "Λόγος", "logos"},
+		{"russian", "Привет, мир", "privet-mir"},
+		{"russian short i and yo", "Йогурт и ёлка", "yogurt-i-yolka"},
+		{"russian signs", "Объявление о съезде", "obyavlenie-o-sezde"},
+		{"ukrainian", "Україна і Львів", "ukrayina-i-lviv"},
+		{"superscript", "x² + y²", "x2-y2"},
+		{"ligature", "ﬁnal ﬂow", "final-flow"},
+		{"fullwidth", "Ｇｏ", "go"},
+		{"mixed scripts", "Go и Python", "go-i-python"},
+		{"untransliterated script", "日本語 guide", "guide"},
+		{"emoji", "Launch 🚀 day", "launch-day"},
+		{"only symbols", "!!! ???", ""},
+		{"only unsupported script", "日本語", ""},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Make(tt.title)
+			if got != tt.want {
+				t.Errorf("Make(%q) = %q, want %q", tt.title, got, tt.want)
+			}
+			if got != "" && !Valid(got) {
+				t.Errorf("Make(%q) = %q, which is not a valid slug", tt.title, got)
+			}
+		})
+	}
+}
+
+func TestMakeTruncates(t *testing.T) {
+	tests := []struct {
+		name  string
+		title string
+		want  string
+	}{
+		{"cuts at a hyphen", strings.Repeat("word ", 30), strings.TrimSuffix(strings.Repeat("word-", 20), "-")},
+		{"one long word", strings.Repeat("a", 150), strings.Repeat("a", MaxLength)},
+		{"hyphen at the limit", strings.Repeat("a", 99) + " b", strings.Repeat("a", 99)},
+		{"exactly the limit", strings.Repeat("a", 98) + " b", strings.Repeat("a", 98) + "-b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Make(tt.title)
+			if got != tt.want {
+				t.Errorf("Make() = %q (%d), want %q (%d)", got, len(got), tt.want, len(tt.want))
+			}
+			if !Valid(got) {
+				t.Errorf("Make() = %q, which is not a valid slug", got)
+			}
+		})
+	}
+}
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		slug string
+		want bool
+	}{
+		{"hello-world", true},
+		{"a", true},
+		{"2024-recap", true},
+		{strings.Repeat("a", MaxLength), true},
+		{"", false},
+		{strings.Repeat("a", MaxLength+1), false},
+		{"Hello-World", false},
+		{"hello world", false},
+		{"hello_world", false},
+		{"hello--world", false},
+		{"-hello", false},
+		{"hello-", false},
+		{"crème", false},
+		{"hello/world", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.slug, func(t *testing.T) {
+			if got := Valid(tt.slug); got != tt.want {
+				t.Errorf("Valid(%q) = %v, want %v", tt.slug, got, tt.want)
+			}
+		})
+	}
+}
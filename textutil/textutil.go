@@ -0,0 +1,15 @@
+// Package textutil holds small string helpers shared across packages.
+package textutil
+
+import (
+	"unicode/utf8"
+)
+
+// Truncate shortens s to at most n characters. It never splits a multi-byte
+// character, so the result stays valid UTF-8 and fits a varchar(n) column.
+func Truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
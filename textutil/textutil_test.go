@@ -0,0 +1,37 @@
+package textutil
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		n    int
+		want string
+	}{
+		{"short", "hello", 10, "hello"},
+		{"exact", "hello", 5, "hello"},
+		{"ascii", "hello world", 5, "hello"},
+		{"two byte characters", "Schlüssel", 5, "Schlü"},
+		{"cut inside a character by bytes", "aü", 2, "aü"},
+		{"cyrillic", "Мой ключ", 3, "Мой"},
+		{"emoji", "🔑🔑🔑", 2, "🔑🔑"},
+		{"empty", "", 3, ""},
+		{"zero", "abc", 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Truncate(tt.s, tt.n)
+			if got != tt.want {
+				t.Errorf("Truncate(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("Truncate(%q, %d) = %q, which is not valid UTF-8", tt.s, tt.n, got)
+			}
+		})
+	}
+}
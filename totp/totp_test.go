@@ -0,0 +1,111 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA1 seed from RFC 6238 appendix B
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+// The RFC lists 8-digit codes; with 6 digits they keep their last six
+var rfcVectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},          // 94287082
+	{1111111109, "081804"},  // 07081804
+	{1111111111, "050471"},  // 14050471
+	{1234567890, "005924"},  // 89005924
+	{2000000000, "279037"},  // 69279037
+	{20000000000, "353130"}, // 65353130
+}
+
+func TestCode(t *testing.T) {
+	for _, tt := range rfcVectors {
+		t.Run(time.Unix(tt.unix, 0).UTC().Format(time.RFC3339), func(t *testing.T) {
+			code, err := Code(rfcSecret, Step(time.Unix(tt.unix, 0)))
+			if err != nil {
+				t.Fatalf("Code() error = %v", err)
+			}
+			if code != tt.code {
+				t.Errorf("Code() = %q, want %q", code, tt.code)
+			}
+		})
+	}
+}
+
+func TestCodeSecretFormatting(t *testing.T) {
+	if _, err := Code("not base32!", 1); err == nil {
+		t.Error("Code() with an invalid secret succeeded")
+	}
+
+	// Secrets are often copied in lowercase or with surrounding whitespace
+	code, err := Code("  "+strings.ToLower(rfcSecret)+"\n", Step(time.Unix(59, 0)))
+	if err != nil {
+		t.Fatalf("Code() error = %v", err)
+	}
+	if code != "287082" {
+		t.Errorf("Code() = %q, want %q", code, "287082")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := Step(now)
+
+	tests := []struct {
+		name     string
+		code     string
+		wantStep int64
+		wantOK   bool
+	}{
+		{"current step", "050471", step, true},
+		{"with spaces", " 050 471 ", step, true},
+		{"previous step", mustCode(t, step-1), step - 1, true},
+		{"next step", mustCode(t, step+1), step + 1, true},
+		{"outside skew", mustCode(t, step-Skew-1), 0, false},
+		{"wrong code", "000000", 0, false},
+		{"too short", "50471", 0, false},
+		{"eight digits", "14050471", 0, false},
+		{"empty", "", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStep, ok := Validate(rfcSecret, tt.code, now)
+			if ok != tt.wantOK || gotStep != tt.wantStep {
+				t.Errorf("Validate() = (%d, %v), want (%d, %v)", gotStep, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret() error = %v", err)
+	}
+	if _, err := Code(secret, 0); err != nil {
+		t.Errorf("Code() with a generated secret error = %v", err)
+	}
+
+	other, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret() error = %v", err)
+	}
+	if secret == other {
+		t.Error("GenerateSecret() returned the same secret twice")
+	}
+}
+
+func mustCode(t *testing.T, step int64) string {
+	t.Helper()
+	code, err := Code(rfcSecret, step)
+	if err != nil {
+		t.Fatalf("Code() error = %v", err)
+	}
+	return code
+}
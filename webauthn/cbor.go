@@ -0,0 +1,213 @@
+package webauthn
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// maxCBORDepth bounds nesting so hostile input cannot exhaust the stack
+const maxCBORDepth = 16
+
+var errCBORTruncated = errors.New("cbor: unexpected end of data")
+
+// cborDecoder decodes the subset of CBOR (RFC 8949) used by WebAuthn:
+// integers, byte and text strings, arrays, maps, tags, booleans, null, and
+// floats. Indefinite-length items are not used by authenticators and are
+// rejected.
+//
+// Values decode to uint64 or int64 integers, []byte, string, []interface{},
+// map[interface{}]interface{}, bool, float64, or nil.
+type cborDecoder struct {
+	data []byte
+	pos  int
+}
+
+// decodeCBOR decodes a single item and returns it with the number of bytes read
+func decodeCBOR(data []byte) (interface{}, int, error) {
+	d := &cborDecoder{data: data}
+	value, err := d.decode(0)
+	if err != nil {
+		return nil, 0, err
+	}
+	return value, d.pos, nil
+}
+
+func (d *cborDecoder) decode(depth int) (interface{}, error) {
+	if depth > maxCBORDepth {
+		return nil, errors.New("cbor: nesting too deep")
+	}
+	if d.pos >= len(d.data) {
+		return nil, errCBORTruncated
+	}
+
+	initial := d.data[d.pos]
+	d.pos++
+	major, info := initial>>5, initial&0x1f
+
+	// Simple values and floats carry their payload in the argument itself
+	if major == 7 {
+		return d.decodeSimple(info)
+	}
+
+	arg, err := d.argument(info)
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case 0:
+		return arg, nil
+	case 1:
+		if arg > math.MaxInt64 {
+			return nil, errors.New("cbor: negative integer out of range")
+		}
+		return -1 - int64(arg), nil
+	case 2, 3:
+		raw, err := d.read(arg)
+		if err != nil {
+			return nil, err
+		}
+		if major == 3 {
+			return string(raw), nil
+		}
+		return append([]byte(nil), raw...), nil
+	case 4:
+		if arg > uint64(len(d.data)-d.pos) {
+			return nil, errCBORTruncated
+		}
+		items := make([]interface{}, 0, arg)
+		for i := uint64(0); i < arg; i++ {
+			item, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case 5:
+		if arg > uint64(len(d.data)-d.pos)/2 {
+			return nil, errCBORTruncated
+		}
+		entries := make(map[interface{}]interface{}, arg)
+		for i := uint64(0); i < arg; i++ {
+			key, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			switch key.(type) {
+			case uint64, int64, string:
+			default:
+				return nil, errors.New("cbor: unsupported map key type")
+			}
+			value, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			if _, duplicate := entries[key]; duplicate {
+				return nil, errors.New("cbor: duplicate map key")
+			}
+			entries[key] = value
+		}
+		return entries, nil
+	default:
+		// Tags only annotate the value that follows
+		return d.decode(depth + 1)
+	}
+}
+
+// argument reads the length or value that follows the initial byte
+func (d *cborDecoder) argument(info byte) (uint64, error) {
+	switch {
+	case info < 24:
+		return uint64(info), nil
+	case info == 24:
+		raw, err := d.read(1)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(raw[0]), nil
+	case info == 25:
+		raw, err := d.read(2)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(binary.BigEndian.Uint16(raw)), nil
+	case info == 26:
+		raw, err := d.read(4)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(binary.BigEndian.Uint32(raw)), nil
+	case info == 27:
+		raw, err := d.read(8)
+		if err != nil {
+			return 0, err
+		}
+		return binary.BigEndian.Uint64(raw), nil
+	default:
+		return 0, fmt.Errorf("cbor: unsupported additional information %d", info)
+	}
+}
+
+func (d *cborDecoder) decodeSimple(info byte) (interface{}, error) {
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		return nil, nil
+	case 25:
+		raw, err := d.read(2)
+		if err != nil {
+			return nil, err
+		}
+		return float64(halfToFloat32(binary.BigEndian.Uint16(raw))), nil
+	case 26:
+		raw, err := d.read(4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(raw))), nil
+	case 27:
+		raw, err := d.read(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(raw)), nil
+	default:
+		return nil, fmt.Errorf("cbor: unsupported simple value %d", info)
+	}
+}
+
+func (d *cborDecoder) read(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, errCBORTruncated
+	}
+	raw := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return raw, nil
+}
+
+// halfToFloat32 converts an IEEE 754 half-precision float
+func halfToFloat32(h uint16) float32 {
+	sign := uint32(h>>15) << 31
+	exponent := uint32(h>>10) & 0x1f
+	mantissa := uint32(h) & 0x3ff
+
+	switch exponent {
+	case 0:
+		// Zero or subnormal
+		value := float32(mantissa) / 1024 / 16384
+		if sign != 0 {
+			value = -value
+		}
+		return value
+	case 0x1f:
+		return math.Float32frombits(sign | 0x7f800000 | mantissa<<13)
+	default:
+		return math.Float32frombits(sign | (exponent+112)<<23 | mantissa<<13)
+	}
+}
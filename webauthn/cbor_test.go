@@ -0,0 +1,173 @@
+package webauthn
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	data, err := hex.DecodeString(strings.ReplaceAll(s, " ", ""))
+	if err != nil {
+		t.Fatalf("invalid hex %q: %v", s, err)
+	}
+	return data
+}
+
+// nested returns depth arrays of one element wrapped around the integer 0
+func nested(depth int) []byte {
+	return append(bytes.Repeat([]byte{0x81}, depth), 0x00)
+}
+
+func TestDecodeCBOR(t *testing.T) {
+	// Examples from RFC 8949 appendix A
+	tests := []struct {
+		name string
+		hex  string
+		want interface{}
+	}{
+		{"zero", "00", uint64(0)},
+		{"small uint", "17", uint64(23)},
+		{"one byte uint", "1818", uint64(24)},
+		{"two byte uint", "1903e8", uint64(1000)},
+		{"four byte uint", "1a000f4240", uint64(1000000)},
+		{"max uint", "1bffffffffffffffff", uint64(math.MaxUint64)},
+		{"negative", "20", int64(-1)},
+		{"COSE ES256", "26", int64(-7)},
+		{"min int", "3b7fffffffffffffff", int64(math.MinInt64)},
+		{"empty bytes", "40", []byte(nil)},
+		{"bytes", "4401020304", []byte{1, 2, 3, 4}},
+		{"text", "6449455446", "IETF"},
+		{"utf-8 text", "62c3bc", "ü"},
+		{"false", "f4", false},
+		{"true", "f5", true},
+		{"null", "f6", nil},
+		{"half float", "f93c00", float64(1)},
+		{"half subnormal", "f90001", 5.960464477539063e-8},
+		{"single float", "fa47c35000", float64(100000)},
+		{"double float", "fb3ff199999999999a", 1.1},
+		{"array", "83010203", []interface{}{uint64(1), uint64(2), uint64(3)}},
+		{"nested array", "8301820203820405", []interface{}{uint64(1), []interface{}{uint64(2), uint64(3)}, []interface{}{uint64(4), uint64(5)}}},
+		{"map", "a201020304", map[interface{}]interface{}{uint64(1): uint64(2), uint64(3): uint64(4)}},
+		{"COSE key", "a3010203262001", map[interface{}]interface{}{uint64(1): uint64(2), uint64(3): int64(-7), int64(-1): uint64(1)}},
+		{"text keys", "a26161016162820203", map[interface{}]interface{}{"a": uint64(1), "b": []interface{}{uint64(2), uint64(3)}}},
+		{"tag", "c11a514b67b0", uint64(1363896240)},
+		{"deepest allowed nesting", hex.EncodeToString(nested(maxCBORDepth)), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := mustHex(t, tt.hex)
+			got, n, err := decodeCBOR(data)
+			if err != nil {
+				t.Fatalf("decodeCBOR() error = %v", err)
+			}
+			if n != len(data) {
+				t.Errorf("decodeCBOR() read %d bytes, want %d", n, len(data))
+			}
+			if tt.want != nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeCBOR() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeCBORTrailingData(t *testing.T) {
+	// Attestation objects are followed by nothing, but authenticator data
+	// embeds a key followed by extensions, so callers rely on the length
+	data := mustHex(t, "a10102 f5 00")
+	value, n, err := decodeCBOR(data)
+	if err != nil {
+		t.Fatalf("decodeCBOR() error = %v", err)
+	}
+	if n != 3 {
+		t.Errorf("decodeCBOR() read %d bytes, want 3", n)
+	}
+	if !reflect.DeepEqual(value, map[interface{}]interface{}{uint64(1): uint64(2)}) {
+		t.Errorf("decodeCBOR() = %#v", value)
+	}
+}
+
+func TestDecodeCBORMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		err  string
+	}{
+		{"empty", nil, "unexpected end of data"},
+		{"truncated argument", []byte{0x19, 0x03}, "unexpected end of data"},
+		{"truncated bytes", []byte{0x44, 0x01, 0x02}, "unexpected end of data"},
+		{"truncated text", []byte{0x64, 'I', 'E'}, "unexpected end of data"},
+		{"truncated array", []byte{0x83, 0x01, 0x02}, "unexpected end of data"},
+		{"truncated map value", []byte{0xa1, 0x01}, "unexpected end of data"},
+		{"truncated tag", []byte{0xc1}, "unexpected end of data"},
+		{"truncated float", []byte{0xfb, 0x3f, 0xf1}, "unexpected end of data"},
+		{"reserved additional info", []byte{0x1c}, "unsupported additional information 28"},
+		{"indefinite bytes", []byte{0x5f, 0x41, 0x01, 0xff}, "unsupported additional information 31"},
+		{"indefinite array", []byte{0x9f, 0x01, 0xff}, "unsupported additional information 31"},
+		{"indefinite map", []byte{0xbf, 0x01, 0x02, 0xff}, "unsupported additional information 31"},
+		{"break outside container", []byte{0xff}, "unsupported simple value 31"},
+		{"unassigned simple value", []byte{0xf0}, "unsupported simple value 16"},
+		{"negative integer out of range", []byte{0x3b, 0x80, 0, 0, 0, 0, 0, 0, 0}, "negative integer out of range"},
+		{"array map key", []byte{0xa1, 0x80, 0x01}, "unsupported map key type"},
+		{"bytes map key", []byte{0xa1, 0x41, 0x00, 0x01}, "unsupported map key type"},
+		{"duplicate map key", []byte{0xa2, 0x01, 0x02, 0x01, 0x03}, "duplicate map key"},
+		{"nesting too deep", nested(maxCBORDepth + 1), "nesting too deep"},
+		{"tags too deep", append(bytes.Repeat([]byte{0xc1}, maxCBORDepth+1), 0x00), "nesting too deep"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, n, err := decodeCBOR(tt.data)
+			if err == nil {
+				t.Fatalf("decodeCBOR() = %#v, %d, want error", value, n)
+			}
+			if !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("decodeCBOR() error = %q, want it to contain %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestDecodeCBOROversized(t *testing.T) {
+	// Declared lengths far beyond the input must fail before anything is allocated
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"bytes", []byte{0x5b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		{"text", []byte{0x7a, 0xff, 0xff, 0xff, 0xff, 'a'}},
+		{"array", []byte{0x9b, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x01}},
+		{"map", []byte{0xbb, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01, 0x02}},
+		{"array longer than remaining bytes", []byte{0x84, 0x01, 0x02, 0x03}},
+		{"map longer than remaining bytes", []byte{0xa3, 0x01, 0x02, 0x03, 0x04}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allocs := testing.AllocsPerRun(1, func() {
+				if _, _, err := decodeCBOR(tt.data); err != errCBORTruncated {
+					t.Errorf("decodeCBOR() error = %v, want %v", err, errCBORTruncated)
+				}
+			})
+			if allocs > 2 {
+				t.Errorf("decodeCBOR() made %v allocations", allocs)
+			}
+		})
+	}
+
+	// A large but well-formed byte string decodes in full
+	payload := bytes.Repeat([]byte{0xab}, 1<<20)
+	data := append([]byte{0x5a, 0x00, 0x10, 0x00, 0x00}, payload...)
+	value, n, err := decodeCBOR(data)
+	if err != nil {
+		t.Fatalf("decodeCBOR() error = %v", err)
+	}
+	if n != len(data) || !bytes.Equal(value.([]byte), payload) {
+		t.Errorf("decodeCBOR() did not return the full byte string")
+	}
+}
@@ -0,0 +1,164 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// COSE algorithm identifiers accepted for credentials
+const (
+	AlgorithmES256 = -7
+	AlgorithmEdDSA = -8
+	AlgorithmRS256 = -257
+)
+
+// SupportedAlgorithms lists the accepted algorithms in order of preference
+var SupportedAlgorithms = []int{AlgorithmES256, AlgorithmEdDSA, AlgorithmRS256}
+
+// COSE key parameters (RFC 9052, RFC 9053)
+const (
+	coseKeyType   = 1
+	coseAlgorithm = 3
+	coseCurve     = -1
+	coseX         = -2 // also the RSA modulus
+	coseY         = -3 // also the RSA exponent
+
+	coseKeyTypeOKP = 1
+	coseKeyTypeEC2 = 2
+	coseKeyTypeRSA = 3
+
+	coseCurveP256    = 1
+	coseCurveEd25519 = 6
+)
+
+// PublicKey is a credential public key decoded from its COSE_Key form
+type PublicKey struct {
+	Algorithm int
+	key       crypto.PublicKey
+}
+
+// ParsePublicKey decodes a CBOR-encoded COSE_Key
+func ParsePublicKey(raw []byte) (*PublicKey, error) {
+	value, n, err := decodeCBOR(raw)
+	if err != nil {
+		return nil, err
+	}
+	if n != len(raw) {
+		return nil, errors.New("webauthn: trailing data after public key")
+	}
+	return parseCOSEKey(value)
+}
+
+func parseCOSEKey(value interface{}) (*PublicKey, error) {
+	params, ok := value.(map[interface{}]interface{})
+	if !ok {
+		return nil, errors.New("webauthn: public key is not a COSE_Key map")
+	}
+
+	keyType, _ := coseInt(params, coseKeyType)
+	algorithm, ok := coseInt(params, coseAlgorithm)
+	if !ok {
+		return nil, errors.New("webauthn: public key has no algorithm")
+	}
+
+	switch {
+	case algorithm == AlgorithmES256 && keyType == coseKeyTypeEC2:
+		if curve, _ := coseInt(params, coseCurve); curve != coseCurveP256 {
+			return nil, errors.New("webauthn: ES256 key is not on P-256")
+		}
+		x, _ := params[int64(coseX)].([]byte)
+		y, _ := params[int64(coseY)].([]byte)
+		if len(x) != 32 || len(y) != 32 {
+			return nil, errors.New("webauthn: invalid P-256 coordinates")
+		}
+
+		// crypto/ecdh rejects points that are not on the curve
+		point := append(append([]byte{4}, x...), y...)
+		if _, err := ecdh.P256().NewPublicKey(point); err != nil {
+			return nil, fmt.Errorf("webauthn: invalid P-256 point: %w", err)
+		}
+		return &PublicKey{Algorithm: algorithm, key: &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}}, nil
+
+	case algorithm == AlgorithmEdDSA && keyType == coseKeyTypeOKP:
+		if curve, _ := coseInt(params, coseCurve); curve != coseCurveEd25519 {
+			return nil, errors.New("webauthn: EdDSA key is not Ed25519")
+		}
+		x, _ := params[int64(coseX)].([]byte)
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("webauthn: invalid Ed25519 key")
+		}
+		return &PublicKey{Algorithm: algorithm, key: ed25519.PublicKey(x)}, nil
+
+	case algorithm == AlgorithmRS256 && keyType == coseKeyTypeRSA:
+		n, _ := params[int64(coseX)].([]byte)
+		e, _ := params[int64(coseY)].([]byte)
+		if len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return nil, errors.New("webauthn: invalid RSA key")
+		}
+		exponent := new(big.Int).SetBytes(e)
+		return &PublicKey{Algorithm: algorithm, key: &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(exponent.Int64()),
+		}}, nil
+
+	default:
+		return nil, fmt.Errorf("webauthn: unsupported key type %d with algorithm %d", keyType, algorithm)
+	}
+}
+
+// Verify checks a signature made by the credential over the message
+func (k *PublicKey) Verify(message, signature []byte) bool {
+	switch key := k.key.(type) {
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(message)
+		return ecdsa.VerifyASN1(key, digest[:], signature)
+	case ed25519.PublicKey:
+		return ed25519.Verify(key, message, signature)
+	case *rsa.PublicKey:
+		digest := sha256.Sum256(message)
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) == nil
+	default:
+		return false
+	}
+}
+
+// coseInt returns an integer parameter of a COSE_Key
+func coseInt(params map[interface{}]interface{}, label int64) (int, bool) {
+	var value interface{}
+	var found bool
+	if label >= 0 {
+		value, found = params[uint64(label)]
+	} else {
+		value, found = params[label]
+	}
+	if !found {
+		return 0, false
+	}
+
+	switch v := value.(type) {
+	case uint64:
+		if v > 1<<31 {
+			return 0, false
+		}
+		return int(v), true
+	case int64:
+		if v < -(1 << 31) {
+			return 0, false
+		}
+		return int(v), true
+	default:
+		return 0, false
+	}
+}
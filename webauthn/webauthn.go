@@ -0,0 +1,295 @@
+// Package webauthn implements the relying party side of Web Authentication
+// (https://www.w3.org/TR/webauthn-2/) for passkey registration and sign-in.
+//
+// Attestation is not verified: registration asks for "none" attestation and
+// trusts the authenticator's public key, which is what passkeys need. The
+// client data, relying party ID, flags, signature, and signature counter are
+// all checked.
+package webauthn
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Client data types
+const (
+	ceremonyCreate = "webauthn.create"
+	ceremonyGet    = "webauthn.get"
+)
+
+// Authenticator data flags
+const (
+	flagUserPresent            = 0x01
+	flagUserVerified           = 0x04
+	flagBackupEligible         = 0x08
+	flagBackupState            = 0x10
+	flagAttestedCredentialData = 0x40
+	flagExtensionData          = 0x80
+)
+
+var (
+	// ErrSignCount means the authenticator's signature counter did not
+	// increase, which suggests the credential was cloned
+	ErrSignCount = errors.New("webauthn: signature counter did not increase")
+
+	// ErrUserVerification means the authenticator did not verify the user
+	// although that was required
+	ErrUserVerification = errors.New("webauthn: user verification required")
+)
+
+// RelyingParty identifies this server to authenticators
+type RelyingParty struct {
+	// ID is the domain credentials are scoped to, e.g. example.com
+	ID string
+	// Name is shown by some authenticators when creating a credential
+	Name string
+	// Origins are the allowed origins of the web pages running the ceremonies
+	Origins []string
+	// RequireUserVerification rejects authenticators that did not verify the
+	// user with a PIN or biometric
+	RequireUserVerification bool
+}
+
+// Credential is a newly registered public key credential
+type Credential struct {
+	ID             []byte
+	PublicKey      []byte
+	Algorithm      int
+	SignCount      uint32
+	AAGUID         []byte
+	UserVerified   bool
+	BackupEligible bool
+	BackupState    bool
+}
+
+// Assertion is the result of a verified sign-in
+type Assertion struct {
+	SignCount    uint32
+	UserVerified bool
+	BackupState  bool
+}
+
+// clientData is the JSON the browser signs over
+type clientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+// authenticatorData is the binary structure produced by the authenticator
+type authenticatorData struct {
+	rpIDHash     []byte
+	flags        byte
+	signCount    uint32
+	aaguid       []byte
+	credentialID []byte
+	publicKey    []byte
+}
+
+// Challenge returns the base64url challenge in the client data, so the caller
+// can look up the ceremony it belongs to before verifying anything
+func Challenge(clientDataJSON []byte) (string, error) {
+	var data clientData
+	if err := json.Unmarshal(clientDataJSON, &data); err != nil {
+		return "", fmt.Errorf("webauthn: invalid client data: %w", err)
+	}
+	if data.Challenge == "" {
+		return "", errors.New("webauthn: client data has no challenge")
+	}
+	return data.Challenge, nil
+}
+
+// VerifyRegistration checks the response to a credential creation request
+// and returns the new credential
+func (rp RelyingParty) VerifyRegistration(challenge string, clientDataJSON, attestationObject []byte) (*Credential, error) {
+	if err := rp.verifyClientData(clientDataJSON, ceremonyCreate, challenge); err != nil {
+		return nil, err
+	}
+
+	value, n, err := decodeCBOR(attestationObject)
+	if err != nil {
+		return nil, err
+	}
+	if n != len(attestationObject) {
+		return nil, errors.New("webauthn: trailing data after attestation object")
+	}
+	attestation, ok := value.(map[interface{}]interface{})
+	if !ok {
+		return nil, errors.New("webauthn: attestation object is not a map")
+	}
+	rawAuthData, ok := attestation["authData"].([]byte)
+	if !ok {
+		return nil, errors.New("webauthn: attestation object has no authData")
+	}
+
+	authData, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+	if err := rp.verifyAuthenticatorData(authData); err != nil {
+		return nil, err
+	}
+	if authData.credentialID == nil {
+		return nil, errors.New("webauthn: no attested credential data")
+	}
+
+	publicKey, err := ParsePublicKey(authData.publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Credential{
+		ID:             authData.credentialID,
+		PublicKey:      authData.publicKey,
+		Algorithm:      publicKey.Algorithm,
+		SignCount:      authData.signCount,
+		AAGUID:         authData.aaguid,
+		UserVerified:   authData.flags&flagUserVerified != 0,
+		BackupEligible: authData.flags&flagBackupEligible != 0,
+		BackupState:    authData.flags&flagBackupState != 0,
+	}, nil
+}
+
+// VerifyAssertion checks the response to a sign-in request against the
+// stored public key and signature counter. A counter that did not increase
+// returns ErrSignCount, unless the authenticator does not keep one (both are 0).
+func (rp RelyingParty) VerifyAssertion(challenge string, publicKey []byte, storedSignCount uint32, clientDataJSON, rawAuthData, signature []byte) (*Assertion, error) {
+	if err := rp.verifyClientData(clientDataJSON, ceremonyGet, challenge); err != nil {
+		return nil, err
+	}
+
+	authData, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+	if err := rp.verifyAuthenticatorData(authData); err != nil {
+		return nil, err
+	}
+
+	key, err := ParsePublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// The signature covers the authenticator data and the client data hash
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := append(append([]byte{}, rawAuthData...), clientDataHash[:]...)
+	if !key.Verify(signed, signature) {
+		return nil, errors.New("webauthn: invalid signature")
+	}
+
+	if (authData.signCount != 0 || storedSignCount != 0) && authData.signCount <= storedSignCount {
+		return nil, ErrSignCount
+	}
+
+	return &Assertion{
+		SignCount:    authData.signCount,
+		UserVerified: authData.flags&flagUserVerified != 0,
+		BackupState:  authData.flags&flagBackupState != 0,
+	}, nil
+}
+
+func (rp RelyingParty) verifyClientData(clientDataJSON []byte, ceremony, challenge string) error {
+	var data clientData
+	if err := json.Unmarshal(clientDataJSON, &data); err != nil {
+		return fmt.Errorf("webauthn: invalid client data: %w", err)
+	}
+	if data.Type != ceremony {
+		return fmt.Errorf("webauthn: client data type is %q, expected %q", data.Type, ceremony)
+	}
+	if subtle.ConstantTimeCompare([]byte(data.Challenge), []byte(challenge)) != 1 {
+		return errors.New("webauthn: challenge mismatch")
+	}
+
+	for _, origin := range rp.Origins {
+		if data.Origin == origin {
+			return nil
+		}
+	}
+	return fmt.Errorf("webauthn: origin %q is not allowed", data.Origin)
+}
+
+func (rp RelyingParty) verifyAuthenticatorData(authData *authenticatorData) error {
+	rpIDHash := sha256.Sum256([]byte(rp.ID))
+	if !bytes.Equal(authData.rpIDHash, rpIDHash[:]) {
+		return errors.New("webauthn: relying party ID mismatch")
+	}
+	if authData.flags&flagUserPresent == 0 {
+		return errors.New("webauthn: user not present")
+	}
+	if rp.RequireUserVerification && authData.flags&flagUserVerified == 0 {
+		return ErrUserVerification
+	}
+	return nil
+}
+
+// parseAuthenticatorData splits authenticator data into its fields
+func parseAuthenticatorData(raw []byte) (*authenticatorData, error) {
+	if len(raw) < 37 {
+		return nil, errors.New("webauthn: authenticator data too short")
+	}
+
+	authData := &authenticatorData{
+		rpIDHash:  raw[:32],
+		flags:     raw[32],
+		signCount: binary.BigEndian.Uint32(raw[33:37]),
+	}
+	rest := raw[37:]
+
+	if authData.flags&flagAttestedCredentialData != 0 {
+		if len(rest) < 18 {
+			return nil, errors.New("webauthn: attested credential data too short")
+		}
+		authData.aaguid = rest[:16]
+		idLength := int(binary.BigEndian.Uint16(rest[16:18]))
+		rest = rest[18:]
+		if idLength == 0 || idLength > 1023 || len(rest) < idLength {
+			return nil, errors.New("webauthn: invalid credential ID length")
+		}
+		authData.credentialID = rest[:idLength]
+		rest = rest[idLength:]
+
+		_, n, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, fmt.Errorf("webauthn: invalid credential public key: %w", err)
+		}
+		authData.publicKey = rest[:n]
+		rest = rest[n:]
+	}
+
+	if authData.flags&flagExtensionData != 0 {
+		_, n, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, fmt.Errorf("webauthn: invalid extension data: %w", err)
+		}
+		rest = rest[n:]
+	}
+
+	if len(rest) != 0 {
+		return nil, errors.New("webauthn: trailing data after authenticator data")
+	}
+
+	return authData, nil
+}
+
+// EncodeBase64 encodes binary values the way WebAuthn JSON does, as unpadded base64url
+func EncodeBase64(raw []byte) string {
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeBase64 decodes base64url, tolerating padding and standard base64
+// characters sent by some clients
+func DecodeBase64(s string) ([]byte, error) {
+	s = string(bytes.TrimRight([]byte(s), "="))
+	if raw, err := base64.RawURLEncoding.DecodeString(s); err == nil {
+		return raw, nil
+	}
+	return base64.RawStdEncoding.DecodeString(s)
+}